@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"text/tabwriter"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+)
+
+// importResult is the outcome of importing one book of a batch.
+type importResult struct {
+	book    edubase.Book
+	pdfPath string
+	err     error
+}
+
+// importBooks imports books one after another with the logged in page. A
+// failing book, also one that runs out of --timeout, does not stop the
+// others, only a canceled context does; books not started by then are
+// reported as canceled.
+func (i *importProcess) importBooks(ctx context.Context, books []edubase.Book) []importResult {
+	results := make([]importResult, 0, len(books))
+
+	for n, book := range books {
+		if err := ctx.Err(); err != nil {
+			results = append(results, importResult{book: book, err: err})
+			continue
+		}
+
+		fmt.Printf("Importing %q (%d of %d)...\n", book.Title, n+1, len(books))
+
+		pdfPath, err := i.importBookWithTimeout(ctx, book)
+		if err != nil {
+			log.Printf("could not import %q: %v", book.Title, err)
+		}
+		results = append(results, importResult{book: book, pdfPath: pdfPath, err: err})
+	}
+
+	return results
+}
+
+// importBookWithTimeout imports book within --timeout, so that every book of
+// a batch gets the whole time. If the book runs out of time, its browser
+// pages are replaced, as calls still pending on them would disturb the next
+// book.
+func (i *importProcess) importBookWithTimeout(ctx context.Context, book edubase.Book) (string, error) {
+	bookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pdfPath, err := i.importBook(bookCtx, book)
+	if err != nil && bookCtx.Err() != nil && ctx.Err() == nil {
+		if resetErr := i.resetPages(); resetErr != nil {
+			return "", errors.Join(err, resetErr)
+		}
+	}
+	return pdfPath, err
+}
+
+// printImportReport writes which books were imported and why the others
+// failed.
+func printImportReport(w io.Writer, results []importResult) {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tTITLE\tRESULT")
+	for _, result := range results {
+		if result.err != nil {
+			fmt.Fprintf(writer, "%d\t%s\t❌ %v\n", result.book.Id, result.book.Title, result.err)
+		} else {
+			fmt.Fprintf(writer, "%d\t%s\t✅ %s\n", result.book.Id, result.book.Title, result.pdfPath)
+		}
+	}
+	writer.Flush()
+}
+
+// countFailed returns the number of books that could not be imported.
+func countFailed(results []importResult) int {
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+	return failed
+}
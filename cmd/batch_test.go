@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+)
+
+func TestPrintImportReport(t *testing.T) {
+	results := []importResult{
+		{book: edubase.Book{Id: 58216, Title: "Mathematik 1"}, pdfPath: "Mathematik 1.pdf"},
+		{book: edubase.Book{Id: 61532, Title: "Deutsch"}, err: errors.New("could not open book")},
+	}
+
+	var buf bytes.Buffer
+	printImportReport(&buf, results)
+
+	expected := "ID     TITLE         RESULT\n" +
+		"58216  Mathematik 1  ✅ Mathematik 1.pdf\n" +
+		"61532  Deutsch       ❌ could not open book\n"
+	if buf.String() != expected {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), expected)
+	}
+
+	if failed := countFailed(results); failed != 1 {
+		t.Errorf("got %d failed books, want 1", failed)
+	}
+}
+
+func TestImportBooksCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	importProcess := &importProcess{}
+	results := importProcess.importBooks(ctx, testBooks[:2])
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	for _, result := range results {
+		if !errors.Is(result.err, context.Canceled) {
+			t.Errorf("book %d: got error %v, want %v", result.book.Id, result.err, context.Canceled)
+		}
+	}
+}
+
+func TestSelectBooks(t *testing.T) {
+	defer func() {
+		allBooks = false
+		bookIds = nil
+		bookTitle = ""
+	}()
+
+	allBooks = true
+	books, _, err := selectBooks(context.Background(), testBooks)
+	if err != nil || len(books) != len(testBooks) {
+		t.Errorf("--all selected %d books (%v), want %d", len(books), err, len(testBooks))
+	}
+
+	if _, _, err := selectBooks(context.Background(), nil); err == nil {
+		t.Errorf("--all with an empty library should have failed")
+	}
+	allBooks = false
+
+	bookIds = []int{61532, 58216, 61532}
+	books, _, err = selectBooks(context.Background(), testBooks)
+	if err != nil {
+		t.Fatalf("--book-id failed: %v", err)
+	}
+	titles := []string{}
+	for _, book := range books {
+		titles = append(titles, book.Title)
+	}
+	if got, want := strings.Join(titles, ", "), "Deutsch: Grammatik / Übungen, Mathematik 1"; got != want {
+		t.Errorf("--book-id selected %s, want %s", got, want)
+	}
+
+	// the other books are imported, the unknown ones reported as failed
+	bookIds = []int{58216, 1, 61532, 2}
+	books, missing, err := selectBooks(context.Background(), testBooks)
+	if err != nil {
+		t.Fatalf("--book-id with unknown ids failed: %v", err)
+	}
+	if len(books) != 2 || books[0].Id != 58216 || books[1].Id != 61532 {
+		t.Errorf("--book-id with unknown ids selected %+v, want books 58216 and 61532", books)
+	}
+	if len(missing) != 2 || missing[0].book.Id != 1 || missing[1].book.Id != 2 {
+		t.Fatalf("--book-id with unknown ids got missing %+v, want books 1 and 2", missing)
+	}
+	for _, result := range missing {
+		if result.err == nil || !strings.Contains(result.err.Error(), "no book with id") {
+			t.Errorf("book %d: got error %v, want no book with id", result.book.Id, result.err)
+		}
+	}
+
+	// a single unknown book fails
+	bookIds = []int{1, 1}
+	if _, _, err := selectBooks(context.Background(), testBooks); err == nil {
+		t.Errorf("--book-id with a single unknown id should have failed")
+	}
+	bookIds = nil
+
+	bookTitle = "physik lösungen"
+	books, _, err = selectBooks(context.Background(), testBooks)
+	if err != nil || len(books) != 1 || books[0].Id != 70002 {
+		t.Errorf("--book-title selected %+v (%v), want book 70002", books, err)
+	}
+}
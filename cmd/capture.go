@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"github.com/schollz/progressbar/v3"
+)
+
+// bookPage is a page of the book and the files it is captured into.
+type bookPage struct {
+	number   int
+	file     string
+	textFile string
+}
+
+// files returns the files of the page as recorded in the manifest.
+func (p bookPage) files() []string {
+	if p.textFile == "" {
+		return []string{p.file}
+	}
+	return []string{p.file, p.textFile}
+}
+
+// pageCapturer captures pages that are not already in the manifest.
+type pageCapturer struct {
+	manifest     *manifest
+	manifestPath string
+	settings     captureSettings
+	bar          *progressbar.ProgressBar
+
+	labelWarning      sync.Once
+	pageNumberWarning sync.Once
+}
+
+// captureRange captures pages in ascending order with a book provider that
+// is open at the first of them. Consecutive pages are reached with the next
+// page button, the first page after a gap is opened directly.
+func (c *pageCapturer) captureRange(ctx context.Context, bookProvider *edubase.BookProvider, pages []bookPage) error {
+	current := pages[0].number
+	for n, page := range pages {
+		if page.number != current {
+			if err := bookProvider.OpenContext(ctx, page.number); err != nil {
+				return fmt.Errorf("could not open page %d: %w", page.number, err)
+			}
+		}
+
+		if imgOverwrite || c.manifest.needsCapture(page.number, page.files(), c.settings) {
+			var previous *bookPage
+			if n > 0 {
+				previous = &pages[n-1]
+			}
+			if err := c.capture(ctx, bookProvider, page, previous); err != nil {
+				return err
+			}
+		}
+
+		// next page
+		if err := bookProvider.NextPageContext(ctx); err != nil {
+			return fmt.Errorf("could not navigate to next page: %w", err)
+		}
+		current = page.number + 1
+
+		c.bar.Add(1)
+	}
+
+	return nil
+}
+
+// capture captures page and records it in the manifest. If the reader is
+// not at page or it looks like previous, the page before it, the reader
+// most likely did not move on. The page is then opened again and captured
+// up to stuckPageRetries times, before it is recorded.
+func (c *pageCapturer) capture(ctx context.Context, bookProvider *edubase.BookProvider, page bookPage, previous *bookPage) error {
+	for attempt := 0; ; attempt++ {
+		if err := c.captureFiles(ctx, bookProvider, page); err != nil {
+			return err
+		}
+
+		shown := c.currentPage(bookProvider, page.number)
+		duplicate, err := c.duplicate(page, previous)
+		if err != nil {
+			return err
+		}
+		if shown == page.number && !duplicate {
+			break
+		}
+
+		if attempt == stuckPageRetries {
+			if shown != page.number {
+				return fmt.Errorf("could not capture page %d: the reader stays at page %d", page.number, shown)
+			}
+			// some pages do look alike, e.g. empty ones
+			log.Printf("page %d still looks like page %d, keeping it", page.number, previous.number)
+			break
+		}
+
+		if shown != page.number {
+			log.Printf("the reader is at page %d instead of page %d, opening it again", shown, page.number)
+		} else {
+			log.Printf("page %d looks like page %d, opening it again", page.number, previous.number)
+		}
+		if err := bookProvider.OpenContext(ctx, page.number); err != nil {
+			return fmt.Errorf("could not open page %d: %w", page.number, err)
+		}
+	}
+
+	label, err := bookProvider.GetPageLabelContext(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		c.labelWarning.Do(func() {
+			log.Printf("could not get page label, the PDF will show page numbers instead: %v", err)
+		})
+	}
+
+	if err := c.manifest.record(page.number, page.files(), c.settings, label); err != nil {
+		return fmt.Errorf("could not record page %d: %w", page.number, err)
+	}
+	if err := c.manifest.save(c.manifestPath); err != nil {
+		return fmt.Errorf("could not save manifest: %w", err)
+	}
+
+	return nil
+}
+
+// currentPage returns the page the reader is at, or page if the URL of the
+// reader does not tell.
+func (c *pageCapturer) currentPage(bookProvider *edubase.BookProvider, page int) int {
+	shown, err := bookProvider.GetCurrentPage()
+	if err != nil {
+		c.pageNumberWarning.Do(func() {
+			log.Printf("could not check the page number of captured pages: %v", err)
+		})
+		return page
+	}
+	return shown
+}
+
+// duplicate tells if the capture of page looks like the one of previous,
+// if that is the page right before it.
+func (c *pageCapturer) duplicate(page bookPage, previous *bookPage) (bool, error) {
+	if previous == nil || previous.number != page.number-1 {
+		return false, nil
+	}
+
+	same, err := samePage(previous.file, page.file)
+	if err != nil {
+		return false, fmt.Errorf("could not compare page %d with page %d: %w", page.number, previous.number, err)
+	}
+	return same, nil
+}
+
+// captureFiles takes the screenshot or SVG of page and saves its text.
+func (c *pageCapturer) captureFiles(ctx context.Context, bookProvider *edubase.BookProvider, page bookPage) error {
+	// wait for page to load
+	if err := edubase.Sleep(ctx, pageDelay); err != nil {
+		return fmt.Errorf("could not wait for page to load: %w", err)
+	}
+
+	// take screenshot or export the page as vector graphic
+	var err error
+	if captureMode == captureModeSVG {
+		err = bookProvider.ExportSVGContext(ctx, page.file)
+	} else {
+		err = bookProvider.ScreenshotContext(ctx, page.file)
+	}
+	if err != nil {
+		return fmt.Errorf("could not take screenshot of page %d: %w", page.number, err)
+	}
+
+	if page.textFile != "" {
+		pageText, err := bookProvider.GetPageTextContext(ctx)
+		if err != nil {
+			return fmt.Errorf("could not get page text of page %d: %w", page.number, err)
+		}
+		if err := savePageText(page.textFile, pageText); err != nil {
+			return fmt.Errorf("could not save page text: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// splitPages splits pages into at most n ranges of nearly the same size,
+// keeping their order.
+func splitPages(pages []bookPage, n int) [][]bookPage {
+	n = max(1, min(n, len(pages)))
+
+	ranges := make([][]bookPage, 0, n)
+	for i := 0; i < n; i++ {
+		first := i * len(pages) / n
+		last := (i + 1) * len(pages) / n
+		ranges = append(ranges, pages[first:last])
+	}
+
+	return ranges
+}
+
+// capturePages captures pages with up to workers browser pages at once. The
+// first worker uses the book provider of the import, which is already open
+// at the first page; the others open their own page of the same browser
+// context at the start of their range. The first error stops all workers.
+func (i *importProcess) capturePages(ctx context.Context, bookId int, pages []bookPage, workers int, capturer *pageCapturer) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var wg sync.WaitGroup
+	for w, pageRange := range splitPages(pages, workers) {
+		if len(pageRange) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var err error
+			if w == 0 {
+				err = capturer.captureRange(ctx, i.bookProvider, pageRange)
+			} else {
+				err = i.captureWithNewPage(ctx, bookId, pageRange, capturer)
+			}
+			if err != nil {
+				cancel(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// nil if all workers are done, otherwise the first error
+	return context.Cause(ctx)
+}
+
+// captureWithNewPage opens a new browser page at the first of pages and
+// captures them.
+func (i *importProcess) captureWithNewPage(ctx context.Context, bookId int, pages []bookPage, capturer *pageCapturer) error {
+	page, err := i.page.Context().NewPage()
+	if err != nil {
+		return fmt.Errorf("could not open browser page: %w", err)
+	}
+	defer page.Close()
+
+	bookProvider := edubase.NewBookProvider(page, bookId, providerOptions()...)
+	if err := bookProvider.OpenContext(ctx, pages[0].number); err != nil {
+		return fmt.Errorf("could not open page %d: %w", pages[0].number, err)
+	}
+
+	return capturer.captureRange(ctx, bookProvider, pages)
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase/edubasetest"
+	"github.com/schollz/progressbar/v3"
+)
+
+func TestSplitPages(t *testing.T) {
+	pages := make([]bookPage, 10)
+	for i := range pages {
+		pages[i].number = i + 1
+	}
+
+	tests := []struct {
+		workers int
+		sizes   []int
+	}{
+		{1, []int{10}},
+		{3, []int{3, 3, 4}},
+		{4, []int{2, 3, 2, 3}},
+		{20, []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		ranges := splitPages(pages, tt.workers)
+		if len(ranges) != len(tt.sizes) {
+			t.Errorf("splitPages(10 pages, %d) returned %d ranges, want %d", tt.workers, len(ranges), len(tt.sizes))
+			continue
+		}
+
+		// the ranges must cover every page once and in order
+		next := 1
+		for i, pageRange := range ranges {
+			if len(pageRange) != tt.sizes[i] {
+				t.Errorf("splitPages(10 pages, %d): range %d has %d pages, want %d", tt.workers, i, len(pageRange), tt.sizes[i])
+			}
+			for _, page := range pageRange {
+				if page.number != next {
+					t.Errorf("splitPages(10 pages, %d): got page %d, want %d", tt.workers, page.number, next)
+				}
+				next++
+			}
+		}
+	}
+
+	if ranges := splitPages(nil, 4); len(ranges) != 1 || len(ranges[0]) != 0 {
+		t.Errorf("splitPages(no pages, 4) = %v, want one empty range", ranges)
+	}
+}
+
+func TestCapturePagesOffline(t *testing.T) {
+	server := edubasetest.NewServer()
+	defer server.Close()
+
+	importProcess, err := newTestImportProcess(edubase.WithBaseURL(server.URL))
+	if err != nil {
+		t.Skipf("Skipping offline test: %v", err)
+	}
+	defer importProcess.close()
+
+	credentials := edubase.Credentials{
+		Email:    edubasetest.Email,
+		Password: edubasetest.Password,
+	}
+	if err := importProcess.loginProvider.Login(credentials, false); err != nil {
+		t.Fatalf("could not login: %v", err)
+	}
+
+	book := server.Books[1]
+	importProcess.bookProvider = edubase.NewBookProvider(importProcess.page, book.Id, edubase.WithBaseURL(server.URL))
+	if err := importProcess.bookProvider.Open(1); err != nil {
+		t.Fatalf("could not open book: %v", err)
+	}
+
+	// the worker pages use the flag of the command
+	oldBaseURL := baseURL
+	baseURL = server.URL
+	defer func() { baseURL = oldBaseURL }()
+
+	dir := t.TempDir()
+	pages := []bookPage{}
+	for i := 1; i <= book.Pages; i++ {
+		pages = append(pages, bookPage{
+			number:   i,
+			file:     fmt.Sprintf("%s/%d_%d.jpeg", dir, book.Id, i),
+			textFile: fmt.Sprintf("%s/%d_%d.json", dir, book.Id, i),
+		})
+	}
+
+	path := manifestPath(dir, book.Id)
+	capturer := &pageCapturer{
+		manifest:     newManifest(book.Id),
+		manifestPath: path,
+		settings:     captureSettings{Mode: captureModeScreenshot, Width: width, Height: height},
+		bar:          progressbar.NewOptions(len(pages), progressbar.OptionSetWriter(io.Discard)),
+	}
+
+	if err := importProcess.capturePages(context.Background(), book.Id, pages, 3, capturer); err != nil {
+		t.Fatalf("could not capture pages: %v", err)
+	}
+
+	for _, page := range pages {
+		if capturer.manifest.needsCapture(page.number, page.files(), capturer.settings) {
+			t.Errorf("page %d was not captured", page.number)
+		}
+
+		pageText, err := loadPageText(page.textFile)
+		if err != nil {
+			t.Fatalf("could not load page text: %v", err)
+		}
+		want := fmt.Sprintf("Page %d of %d", page.number, book.Pages)
+		found := false
+		for _, run := range pageText.Runs {
+			found = found || run.Text == want
+		}
+		if !found {
+			t.Errorf("page %d holds the wrong page, want %q in %+v", page.number, want, pageText.Runs)
+		}
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("manifest was not saved: %v", err)
+	}
+}
+
+func TestCapturePagesStuckOffline(t *testing.T) {
+	server := edubasetest.NewServer()
+	defer server.Close()
+
+	// the reader stays at page 3 when it should move to page 4
+	server.Books[1].StuckPages = []int{3}
+	book := server.Books[1]
+
+	importProcess, err := newTestImportProcess(edubase.WithBaseURL(server.URL))
+	if err != nil {
+		t.Skipf("Skipping offline test: %v", err)
+	}
+	defer importProcess.close()
+
+	credentials := edubase.Credentials{
+		Email:    edubasetest.Email,
+		Password: edubasetest.Password,
+	}
+	if err := importProcess.loginProvider.Login(credentials, false); err != nil {
+		t.Fatalf("could not login: %v", err)
+	}
+
+	importProcess.bookProvider = edubase.NewBookProvider(importProcess.page, book.Id, edubase.WithBaseURL(server.URL))
+	if err := importProcess.bookProvider.Open(1); err != nil {
+		t.Fatalf("could not open book: %v", err)
+	}
+
+	dir := t.TempDir()
+	pages := []bookPage{}
+	for i := 1; i <= 6; i++ {
+		pages = append(pages, bookPage{
+			number:   i,
+			file:     fmt.Sprintf("%s/%d_%d.jpeg", dir, book.Id, i),
+			textFile: fmt.Sprintf("%s/%d_%d.json", dir, book.Id, i),
+		})
+	}
+
+	capturer := &pageCapturer{
+		manifest:     newManifest(book.Id),
+		manifestPath: manifestPath(dir, book.Id),
+		settings:     captureSettings{Mode: captureModeScreenshot, Width: width, Height: height},
+		bar:          progressbar.NewOptions(len(pages), progressbar.OptionSetWriter(io.Discard)),
+	}
+
+	if err := importProcess.capturePages(context.Background(), book.Id, pages, 1, capturer); err != nil {
+		t.Fatalf("could not capture pages: %v", err)
+	}
+
+	for _, page := range pages {
+		pageText, err := loadPageText(page.textFile)
+		if err != nil {
+			t.Fatalf("could not load page text: %v", err)
+		}
+		want := fmt.Sprintf("Page %d of %d", page.number, book.Pages)
+		found := false
+		for _, run := range pageText.Runs {
+			found = found || run.Text == want
+		}
+		if !found {
+			t.Errorf("page %d holds the wrong page, want %q in %+v", page.number, want, pageText.Runs)
+		}
+	}
+}
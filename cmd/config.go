@@ -0,0 +1,405 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	configEnvPrefix = "EDUBASE_"
+
+	sourceFlag     = "flag"
+	sourceEnv      = "env"
+	sourceDefaults = "config defaults"
+	sourceBuiltIn  = "default"
+)
+
+var configPath string = defaultConfigFile("config.yaml")
+var configProfile string = ""
+
+// unconfigurable are the flags that cannot be set by the config file or
+// environment: the password and where to read it from belong to a single
+// run or the credential store, and the config file and profile have to be
+// known before the config file is read.
+var unconfigurable = map[string]bool{
+	"help":           true,
+	"password":       true,
+	"password-stdin": true,
+	"password-file":  true,
+	"config":         true,
+	"config-profile": true,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", configPath, "Config file with default settings and profiles.")
+	rootCmd.PersistentFlags().StringVar(&configProfile, "config-profile", configProfile, "Profile of the config file to use. Defaults to $EDUBASE_CONFIG_PROFILE or default_profile of the config file.")
+
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+	Long: `Description:
+  Settings are taken from, in this order: flags, environment variables named EDUBASE_ and the flag
+  name in upper case (e.g. EDUBASE_PAGE_DELAY), the selected profile of the config file, the
+  defaults of the config file and the built-in defaults. The config file uses the flag names as keys:
+
+    default_profile: school
+    defaults:
+      width: 1920
+      page-delay: 1s
+    profiles:
+      school:
+        login-method: microsoft
+        profile: school
+
+Example:
+  edubase-to-pdf config show import --config-profile school
+
+  This example prints the settings the import command would use with the profile "school".
+
+Contact:
+  For any issues or questions, please open an issue on the GitHub repository:
+  https://github.com/michaelbeutler/edubase-to-pdf/issues`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show [command] [flags]",
+	Short: "Print the effective settings of a command",
+	Long: `Description:
+  Prints the settings the command would use with the given flags, and where every setting is taken
+  from. The command defaults to import.
+
+Example:
+  edubase-to-pdf config show import --config-profile school --book-title "mathematik 1"
+
+Contact:
+  For any issues or questions, please open an issue on the GitHub repository:
+  https://github.com/michaelbeutler/edubase-to-pdf/issues`,
+	// the flags belong to the command whose settings are shown
+	DisableFlagParsing: true,
+	// the config is applied to that command, not to show itself
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		target, flags, err := configShowTarget(args)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+
+		target.InitDefaultHelpFlag()
+		if err := target.ParseFlags(flags); err != nil {
+			log.Fatalf("%v", err)
+		}
+		if help, _ := target.Flags().GetBool("help"); help {
+			cmd.Help()
+			return
+		}
+		if err := target.ValidateFlagGroups(); err != nil {
+			log.Fatalf("%v", err)
+		}
+
+		settings, err := applyConfig(target)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+
+		if err := printSettings(os.Stdout, settings); err != nil {
+			log.Fatalf("could not print settings: %v", err)
+		}
+	},
+}
+
+// configShowTarget returns the command named by the first of args, import if
+// args start with a flag, and the flags that follow.
+func configShowTarget(args []string) (*cobra.Command, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return importCmd, args, nil
+	}
+
+	c, _, err := rootCmd.Find(args[:1])
+	if err != nil || c == rootCmd {
+		return nil, nil, fmt.Errorf("unknown command %q", args[0])
+	}
+	return c, args[1:], nil
+}
+
+// configFile is the content of the config file. Keys are flag names.
+type configFile struct {
+	DefaultProfile string                            `yaml:"default_profile"`
+	Defaults       map[string]interface{}            `yaml:"defaults"`
+	Profiles       map[string]map[string]interface{} `yaml:"profiles"`
+}
+
+// setting is the value of a flag and where it was taken from.
+type setting struct {
+	name   string
+	value  string
+	source string
+}
+
+// loadConfigFile reads the config file at path. A missing file is only an
+// error if it was given with --config.
+func loadConfigFile(path string, required bool) (configFile, error) {
+	var config configFile
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return config, nil
+	}
+	if err != nil {
+		return config, fmt.Errorf("could not read config: %w", err)
+	}
+
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return config, fmt.Errorf("could not parse config %s: %w", path, err)
+	}
+
+	known := configurableFlags()
+	if err := checkConfigKeys(config.Defaults, "defaults", known); err != nil {
+		return config, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	for name, profile := range config.Profiles {
+		if err := checkConfigKeys(profile, "profile "+name, known); err != nil {
+			return config, fmt.Errorf("invalid config %s: %w", path, err)
+		}
+	}
+
+	return config, nil
+}
+
+// checkConfigKeys returns an error for keys that are no flag of any command,
+// most likely typos.
+func checkConfigKeys(values map[string]interface{}, section string, known map[string]bool) error {
+	for key := range values {
+		if key == "password" || key == "password-stdin" || key == "password-file" {
+			return fmt.Errorf("%s: do not store the password in the config file, use \"credentials set\" and \"profile\"", section)
+		}
+		if !known[key] {
+			return fmt.Errorf("%s: unknown setting %q", section, key)
+		}
+	}
+	return nil
+}
+
+// configurableFlags returns the names of the flags of all commands that can
+// be set by the config file.
+func configurableFlags() map[string]bool {
+	known := map[string]bool{}
+
+	var visit func(c *cobra.Command)
+	visit = func(c *cobra.Command) {
+		visitCommandFlags(c, func(f *pflag.Flag) {
+			known[f.Name] = true
+		})
+		for _, child := range c.Commands() {
+			visit(child)
+		}
+	}
+	visit(rootCmd)
+
+	return known
+}
+
+// visitCommandFlags calls fn for the configurable flags of c, including the
+// ones it inherits.
+func visitCommandFlags(c *cobra.Command, fn func(f *pflag.Flag)) {
+	seen := map[string]bool{}
+	for _, flags := range []*pflag.FlagSet{c.LocalFlags(), c.InheritedFlags()} {
+		flags.VisitAll(func(f *pflag.Flag) {
+			if unconfigurable[f.Name] || seen[f.Name] {
+				return
+			}
+			seen[f.Name] = true
+			fn(f)
+		})
+	}
+}
+
+// configEnv returns the environment variable of the flag name, e.g.
+// EDUBASE_PAGE_DELAY for page-delay.
+func configEnv(name string) string {
+	return configEnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// mutuallyExclusiveAnnotation is the annotation cobra stores the groups of
+// MarkFlagsMutuallyExclusive in, as flag names separated by spaces.
+const mutuallyExclusiveAnnotation = "cobra_annotation_mutually_exclusive"
+
+// Priorities of the sources of a setting, a lower one wins.
+const (
+	priorityFlag = iota
+	priorityEnv
+	priorityProfile
+	priorityDefaults
+	priorityBuiltIn
+)
+
+// candidate is the value a flag would get from its highest priority source.
+type candidate struct {
+	flag     *pflag.Flag
+	value    string
+	source   string
+	priority int
+}
+
+// applyConfig sets the flags of c that were not given on the command line
+// from the environment and the config file, and returns where every setting
+// was taken from. Flags that cannot be used together are only set from the
+// source with the highest priority, so that e.g. --book-title on the command
+// line is not overridden by book-id of the config file.
+func applyConfig(c *cobra.Command) ([]setting, error) {
+	configFlag := rootCmd.PersistentFlags().Lookup("config")
+	config, err := loadConfigFile(configPath, configFlag != nil && configFlag.Changed)
+	if err != nil {
+		return nil, err
+	}
+
+	profileName := configProfile
+	if profileName == "" {
+		profileName = os.Getenv(configEnv("config-profile"))
+	}
+	if profileName == "" {
+		profileName = config.DefaultProfile
+	}
+
+	var profile map[string]interface{}
+	if profileName != "" {
+		var ok bool
+		profile, ok = config.Profiles[profileName]
+		if !ok {
+			return nil, fmt.Errorf("there is no profile %q in config %s", profileName, configPath)
+		}
+	}
+
+	candidates := map[string]*candidate{}
+	var names []string
+	visitCommandFlags(c, func(f *pflag.Flag) {
+		cand := &candidate{flag: f, source: sourceBuiltIn, priority: priorityBuiltIn}
+
+		if f.Changed {
+			cand.source, cand.priority = sourceFlag, priorityFlag
+		} else if value, found := os.LookupEnv(configEnv(f.Name)); found {
+			cand.value, cand.source, cand.priority = value, sourceEnv+" "+configEnv(f.Name), priorityEnv
+		} else if v, ok := profile[f.Name]; ok {
+			cand.value, cand.source, cand.priority = configValue(v), "profile "+profileName, priorityProfile
+		} else if v, ok := config.Defaults[f.Name]; ok {
+			cand.value, cand.source, cand.priority = configValue(v), sourceDefaults, priorityDefaults
+		}
+
+		candidates[f.Name] = cand
+		names = append(names, f.Name)
+	})
+
+	var errs []error
+	for _, group := range exclusiveGroups(c) {
+		errs = append(errs, resolveExclusive(group, candidates)...)
+	}
+
+	var settings []setting
+	for _, name := range names {
+		cand := candidates[name]
+		if cand.priority != priorityFlag && cand.priority != priorityBuiltIn {
+			if err := cand.flag.Value.Set(cand.value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid value %q for %s from %s: %w", cand.value, name, cand.source, err))
+			}
+		}
+
+		settings = append(settings, setting{name: name, value: cand.flag.Value.String(), source: cand.source})
+	}
+
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].name < settings[j].name
+	})
+
+	return settings, errors.Join(errs...)
+}
+
+// exclusiveGroups returns the groups of flags of c that cannot be used
+// together.
+func exclusiveGroups(c *cobra.Command) [][]string {
+	seen := map[string]bool{}
+	var groups [][]string
+	c.Flags().VisitAll(func(f *pflag.Flag) {
+		for _, group := range f.Annotations[mutuallyExclusiveAnnotation] {
+			if !seen[group] {
+				seen[group] = true
+				groups = append(groups, strings.Split(group, " "))
+			}
+		}
+	})
+	return groups
+}
+
+// resolveExclusive drops the values of the flags of group that come from a
+// source with a lower priority than another flag of the group. Flags of the
+// group set by the same source other than the command line, where cobra
+// reports them, are an error.
+func resolveExclusive(group []string, candidates map[string]*candidate) []error {
+	best := priorityBuiltIn
+	for _, name := range group {
+		if cand, ok := candidates[name]; ok && cand.priority < best {
+			best = cand.priority
+		}
+	}
+	if best == priorityBuiltIn {
+		return nil
+	}
+
+	var set []string
+	for _, name := range group {
+		cand, ok := candidates[name]
+		if !ok || cand.priority == priorityBuiltIn {
+			continue
+		}
+		if cand.priority > best {
+			cand.value, cand.source, cand.priority = "", sourceBuiltIn, priorityBuiltIn
+			continue
+		}
+		set = append(set, name)
+	}
+
+	if best == priorityFlag || len(set) < 2 {
+		return nil
+	}
+	return []error{fmt.Errorf("settings %s from %s cannot be used together", strings.Join(set, ", "), candidates[set[0]].source)}
+}
+
+// configValue converts a YAML value to the text a flag parses, lists are
+// joined with commas.
+func configValue(v interface{}) string {
+	if list, ok := v.([]interface{}); ok {
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			values = append(values, fmt.Sprint(item))
+		}
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// printSettings writes settings to w as a table.
+func printSettings(w io.Writer, settings []setting) error {
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "SETTING\tVALUE\tSOURCE")
+	for _, s := range settings {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", s.name, s.value, s.source)
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// setConfig writes content to a config file and selects it and profile for
+// the test.
+func setConfig(t *testing.T, content string, profile string) {
+	t.Helper()
+
+	oldPath, oldProfile := configPath, configProfile
+	t.Cleanup(func() { configPath, configProfile = oldPath, oldProfile })
+
+	configPath = filepath.Join(t.TempDir(), "config.yaml")
+	configProfile = profile
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newConfigTestCommand returns a command with some of the import flags
+// bound to its own variables.
+func newConfigTestCommand() (*cobra.Command, *int, *int, *time.Duration, *[]int) {
+	var w, h int = 2560, 1440
+	var delay time.Duration = 500 * time.Millisecond
+	var ids []int
+
+	c := &cobra.Command{Use: "test"}
+	c.Flags().IntVarP(&w, "width", "W", w, "")
+	c.Flags().IntVarP(&h, "height", "H", h, "")
+	c.Flags().DurationVarP(&delay, "page-delay", "D", delay, "")
+	c.Flags().IntSliceVar(&ids, "book-id", ids, "")
+
+	return c, &w, &h, &delay, &ids
+}
+
+func TestApplyConfig(t *testing.T) {
+	setConfig(t, `default_profile: school
+defaults:
+  width: 1920
+  height: 1080
+  page-delay: 1s
+profiles:
+  school:
+    height: 900
+    book-id: [58216, 61532]
+`, "")
+	t.Setenv("EDUBASE_PAGE_DELAY", "2s")
+	t.Setenv("EDUBASE_CONFIG_PROFILE", "")
+
+	c, w, h, delay, ids := newConfigTestCommand()
+	if err := c.ParseFlags([]string{"--width", "100"}); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := applyConfig(c)
+	if err != nil {
+		t.Fatalf("apply config failed: %v", err)
+	}
+
+	if *w != 100 {
+		t.Errorf("flag: got width %d, want %d", *w, 100)
+	}
+	if *delay != 2*time.Second {
+		t.Errorf("env: got page delay %s, want %s", *delay, 2*time.Second)
+	}
+	if *h != 900 {
+		t.Errorf("profile: got height %d, want %d", *h, 900)
+	}
+	if len(*ids) != 2 || (*ids)[0] != 58216 || (*ids)[1] != 61532 {
+		t.Errorf("profile: got book ids %v, want %v", *ids, []int{58216, 61532})
+	}
+
+	sources := map[string]string{}
+	for _, s := range settings {
+		sources[s.name] = s.source
+	}
+	want := map[string]string{
+		"width":      sourceFlag,
+		"page-delay": "env EDUBASE_PAGE_DELAY",
+		"height":     "profile school",
+		"book-id":    "profile school",
+	}
+	for name, source := range want {
+		if sources[name] != source {
+			t.Errorf("%s: got source %q, want %q", name, sources[name], source)
+		}
+	}
+}
+
+func TestApplyConfigDefaults(t *testing.T) {
+	setConfig(t, "defaults:\n  height: 1080\n", "")
+	t.Setenv("EDUBASE_CONFIG_PROFILE", "")
+
+	c, w, h, _, _ := newConfigTestCommand()
+	if _, err := applyConfig(c); err != nil {
+		t.Fatalf("apply config failed: %v", err)
+	}
+
+	if *h != 1080 {
+		t.Errorf("got height %d, want %d", *h, 1080)
+	}
+	if *w != 2560 {
+		t.Errorf("got width %d, want built-in %d", *w, 2560)
+	}
+}
+
+func TestApplyConfigExclusive(t *testing.T) {
+	setConfig(t, `defaults:
+  book-id: [58216]
+  pages: "1-10"
+profiles:
+  school:
+    book-title: Mathe
+`, "school")
+	t.Setenv("EDUBASE_CONFIG_PROFILE", "")
+
+	c, _, _, _, ids := newConfigTestCommand()
+	var title, pages string
+	var start int = 1
+	c.Flags().StringVar(&title, "book-title", title, "")
+	c.Flags().StringVar(&pages, "pages", pages, "")
+	c.Flags().IntVar(&start, "start-page", start, "")
+	c.MarkFlagsMutuallyExclusive("book-id", "book-title")
+	c.MarkFlagsMutuallyExclusive("pages", "start-page")
+
+	if err := c.ParseFlags([]string{"--start-page", "3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := applyConfig(c); err != nil {
+		t.Fatalf("apply config failed: %v", err)
+	}
+
+	// the profile wins over the defaults, the command line over both
+	if len(*ids) != 0 || title != "Mathe" {
+		t.Errorf("got book ids %v and title %q, want only title %q", *ids, title, "Mathe")
+	}
+	if pages != "" || start != 3 {
+		t.Errorf("got pages %q and start page %d, want only start page %d", pages, start, 3)
+	}
+}
+
+func TestApplyConfigExclusiveSameSource(t *testing.T) {
+	setConfig(t, "defaults:\n  book-id: [58216]\n  book-title: Mathe\n", "")
+	t.Setenv("EDUBASE_CONFIG_PROFILE", "")
+
+	c, _, _, _, _ := newConfigTestCommand()
+	var title string
+	c.Flags().StringVar(&title, "book-title", title, "")
+	c.MarkFlagsMutuallyExclusive("book-id", "book-title")
+
+	_, err := applyConfig(c)
+	if err == nil || !strings.Contains(err.Error(), "cannot be used together") {
+		t.Errorf("got error %v, want it to contain %q", err, "cannot be used together")
+	}
+}
+
+func TestConfigShowTarget(t *testing.T) {
+	tests := []struct {
+		args      []string
+		wantCmd   *cobra.Command
+		wantFlags []string
+	}{
+		{args: nil, wantCmd: importCmd},
+		{args: []string{"--book-title", "Mathe"}, wantCmd: importCmd, wantFlags: []string{"--book-title", "Mathe"}},
+		{args: []string{"list", "--format", "json"}, wantCmd: listCmd, wantFlags: []string{"--format", "json"}},
+	}
+
+	for _, tt := range tests {
+		c, flags, err := configShowTarget(tt.args)
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", tt.args, err)
+			continue
+		}
+		if c != tt.wantCmd || strings.Join(flags, " ") != strings.Join(tt.wantFlags, " ") {
+			t.Errorf("%v: got %s %v, want %s %v", tt.args, c.Name(), flags, tt.wantCmd.Name(), tt.wantFlags)
+		}
+	}
+
+	if _, _, err := configShowTarget([]string{"bogus"}); err == nil {
+		t.Error("expected error for unknown command")
+	}
+}
+
+func TestApplyConfigErrors(t *testing.T) {
+	t.Setenv("EDUBASE_CONFIG_PROFILE", "")
+
+	tests := []struct {
+		name    string
+		content string
+		profile string
+		wantErr string
+	}{
+		{name: "unknown profile", content: "profiles:\n  school:\n    width: 1\n", profile: "work", wantErr: "no profile \"work\""},
+		{name: "unknown setting", content: "defaults:\n  widht: 1\n", wantErr: "unknown setting \"widht\""},
+		{name: "password", content: "defaults:\n  password: secret\n", wantErr: "do not store the password"},
+		{name: "password stdin", content: "defaults:\n  password-stdin: true\n", wantErr: "do not store the password"},
+		{name: "password file", content: "profiles:\n  school:\n    password-file: pw.txt\n", profile: "school", wantErr: "do not store the password"},
+		{name: "unknown section", content: "default:\n  width: 1\n", wantErr: "default"},
+		{name: "invalid value", content: "defaults:\n  width: wide\n", wantErr: "invalid value \"wide\" for width"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfig(t, tt.content, tt.profile)
+
+			c, _, _, _, _ := newConfigTestCommand()
+			_, err := applyConfig(c)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestApplyConfigPasswordEnv(t *testing.T) {
+	setConfig(t, "", "")
+	t.Setenv("EDUBASE_CONFIG_PROFILE", "")
+	t.Setenv("EDUBASE_PASSWORD_STDIN", "true")
+	t.Setenv("EDUBASE_PASSWORD_FILE", "pw.txt")
+
+	var stdin bool
+	var file string
+	c := &cobra.Command{Use: "test"}
+	c.Flags().BoolVar(&stdin, "password-stdin", false, "")
+	c.Flags().StringVar(&file, "password-file", "", "")
+
+	settings, err := applyConfig(c)
+	if err != nil {
+		t.Fatalf("apply config failed: %v", err)
+	}
+
+	if stdin || file != "" {
+		t.Errorf("password flags were set from the environment: password-stdin %v, password-file %q", stdin, file)
+	}
+	if len(settings) != 0 {
+		t.Errorf("got settings %v, want none", settings)
+	}
+}
+
+func TestApplyConfigMissingFile(t *testing.T) {
+	defer func(path string) { configPath = path }(configPath)
+	configPath = filepath.Join(t.TempDir(), "missing.yaml")
+
+	c, _, _, _, _ := newConfigTestCommand()
+	if _, err := applyConfig(c); err != nil {
+		t.Errorf("missing default config should be ignored: %v", err)
+	}
+}
+
+func TestConfigEnv(t *testing.T) {
+	if got := configEnv("page-delay"); got != "EDUBASE_PAGE_DELAY" {
+		t.Errorf("got %q, want %q", got, "EDUBASE_PAGE_DELAY")
+	}
+}
+
+func TestPrintSettings(t *testing.T) {
+	var buf bytes.Buffer
+	err := printSettings(&buf, []setting{
+		{name: "width", value: "1920", source: sourceDefaults},
+		{name: "page-delay", value: "2s", source: "env EDUBASE_PAGE_DELAY"},
+	})
+	if err != nil {
+		t.Fatalf("print settings failed: %v", err)
+	}
+
+	expected := "SETTING     VALUE  SOURCE\n" +
+		"width       1920   config defaults\n" +
+		"page-delay  2s     env EDUBASE_PAGE_DELAY\n"
+	if buf.String() != expected {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), expected)
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/charmbracelet/huh/spinner"
+)
+
+// newCommandContext returns a context that is canceled on Ctrl+C or SIGTERM.
+// --timeout is applied by the commands, so that time spent in prompts does
+// not count.
+func newCommandContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+}
+
+// runWithSpinner shows a spinner with the given title while action runs.
+// The spinner reads the terminal in raw mode, so Ctrl+C never reaches the
+// signal handler; pressing it cancels the context passed to action instead.
+func runWithSpinner(ctx context.Context, title string, action func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	spinnerCtx, stopSpinner := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- action(ctx)
+		stopSpinner()
+	}()
+
+	if err := spinner.New().Title(title).Context(spinnerCtx).Run(); err != nil {
+		cancel()
+		<-done
+		return err
+	}
+
+	// the spinner stopped before the action finished: the user pressed Ctrl+C
+	if spinnerCtx.Err() == nil {
+		cancel()
+	}
+
+	return <-done
+}
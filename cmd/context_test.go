@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+)
+
+func TestNewCommandContextNoDeadline(t *testing.T) {
+	ctx, cancel := newCommandContext(context.Background())
+	defer cancel()
+
+	// prompts run under this context, --timeout only starts after them
+	if _, ok := ctx.Deadline(); ok {
+		t.Errorf("newCommandContext has a deadline; want none")
+	}
+}
+
+func TestNewCommandContextCancel(t *testing.T) {
+	ctx, cancel := newCommandContext(context.Background())
+	cancel()
+
+	if err := edubase.Sleep(ctx, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Errorf("Sleep = %v; want %v", err, context.Canceled)
+	}
+}
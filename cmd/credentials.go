@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/credstore"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+const (
+	emailEnv      = "EDUBASE_EMAIL"
+	passwordEnv   = "EDUBASE_PASSWORD"
+	passphraseEnv = "EDUBASE_PASSPHRASE"
+)
+
+var passwordStdin bool = false
+var passwordFile string = ""
+var profile string = ""
+var loginMethod string = string(edubase.LoginMethodEdubase)
+
+// resolveCredentials returns the credentials to log in with. With --profile
+// they are read from the credential store. Otherwise the email is taken from
+// --email or EDUBASE_EMAIL, the password from --password, --password-stdin,
+// --password-file or EDUBASE_PASSWORD, in this order. Whatever is still
+// missing is asked for on the terminal, without showing the password.
+func resolveCredentials(stdin *os.File, prompt io.Writer) (edubase.Credentials, error) {
+	if profile != "" {
+		store, err := loadCredentialStore(stdin, prompt, false)
+		if err != nil {
+			return edubase.Credentials{}, err
+		}
+		return store.Get(profile)
+	}
+
+	credentials := edubase.Credentials{
+		Email:    email,
+		Password: password,
+	}
+
+	if credentials.Email == "" {
+		credentials.Email = os.Getenv(emailEnv)
+	}
+
+	switch {
+	case credentials.Password != "":
+	case passwordStdin:
+		if credentials.Email == "" {
+			return edubase.Credentials{}, fmt.Errorf("--password-stdin needs the email from --email or %s", emailEnv)
+		}
+		p, err := edubase.ReadPassword(stdin)
+		if err != nil {
+			return edubase.Credentials{}, fmt.Errorf("could not read password from stdin: %w", err)
+		}
+		credentials.Password = p
+	case passwordFile != "":
+		p, err := readPasswordFile(passwordFile)
+		if err != nil {
+			return edubase.Credentials{}, err
+		}
+		credentials.Password = p
+	default:
+		credentials.Password = os.Getenv(passwordEnv)
+	}
+
+	if credentials.Email == "" {
+		e, err := edubase.PromptEmail(stdin, prompt)
+		if err != nil {
+			return edubase.Credentials{}, err
+		}
+		credentials.Email = e
+	}
+
+	if credentials.Password == "" {
+		p, err := edubase.PromptPassword(stdin, prompt)
+		if errors.Is(err, edubase.ErrNotTerminal) {
+			return edubase.Credentials{}, fmt.Errorf("%w: pass the password with --password-stdin, --password-file or %s", err, passwordEnv)
+		}
+		if err != nil {
+			return edubase.Credentials{}, err
+		}
+		credentials.Password = p
+	}
+
+	return credentials, nil
+}
+
+// credentialFlags are the flags that give the credentials to log in with.
+var credentialFlags = []string{"profile", "email", "password", "password-stdin", "password-file"}
+
+// credentialsGiven reports whether credentials were passed as flags. The
+// environment and the config file do not count, they are only used once
+// there is no session to use.
+func credentialsGiven(flags *pflag.FlagSet) bool {
+	for _, name := range credentialFlags {
+		if f := flags.Lookup(name); f != nil && f.Changed {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLoginMethod returns an error if --login-method is not supported.
+func checkLoginMethod() error {
+	switch edubase.LoginMethod(loginMethod) {
+	case edubase.LoginMethodEdubase, edubase.LoginMethodMicrosoft:
+		return nil
+	}
+	return fmt.Errorf("invalid login method %q: must be %q or %q", loginMethod, edubase.LoginMethodEdubase, edubase.LoginMethodMicrosoft)
+}
+
+// readPassphrase returns the passphrase of the credential store from
+// EDUBASE_PASSPHRASE, or asks for it on the terminal. With confirm, the
+// passphrase has to be typed twice.
+func readPassphrase(stdin *os.File, prompt io.Writer, confirm bool) ([]byte, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return []byte(passphrase), nil
+	}
+
+	fd := int(stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return nil, fmt.Errorf("could not read passphrase: %w: set %s", edubase.ErrNotTerminal, passphraseEnv)
+	}
+
+	fmt.Fprint(prompt, "Passphrase: ")
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("could not read passphrase: %w", err)
+	}
+
+	if confirm {
+		fmt.Fprint(prompt, "Repeat passphrase: ")
+		repeated, err := term.ReadPassword(fd)
+		fmt.Fprintln(prompt)
+		if err != nil {
+			return nil, fmt.Errorf("could not read passphrase: %w", err)
+		}
+		if !bytes.Equal(passphrase, repeated) {
+			return nil, errors.New("passphrases do not match")
+		}
+	}
+
+	return passphrase, nil
+}
+
+// loadCredentialStore opens the credential store of --credentials-file. If
+// create is false, the store has to exist.
+func loadCredentialStore(stdin *os.File, prompt io.Writer, create bool) (*credstore.Store, error) {
+	if credentialsPath == "" {
+		return nil, errors.New("no credential store: set --credentials-file")
+	}
+
+	exists := credstore.Exists(credentialsPath)
+	if !exists && !create {
+		return nil, fmt.Errorf("there is no credential store at %s, add credentials with \"credentials set\"", credentialsPath)
+	}
+
+	// a typo in the passphrase of a new store would lock the user out
+	passphrase, err := readPassphrase(stdin, prompt, !exists)
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := credstore.Load(credentialsPath, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("could not open credential store: %w", err)
+	}
+
+	return store, nil
+}
+
+// readPasswordFile reads the password from the first line of the file.
+func readPasswordFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("could not open password file: %w", err)
+	}
+	defer file.Close()
+
+	p, err := edubase.ReadPassword(file)
+	if err != nil {
+		return "", fmt.Errorf("could not read password file %s: %w", path, err)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+)
+
+// setCredentialFlags sets the credential flags for the test and restores
+// them afterwards.
+func setCredentialFlags(t *testing.T, e, p string, stdin bool, file string) {
+	t.Helper()
+
+	oldEmail, oldPassword, oldStdin, oldFile := email, password, passwordStdin, passwordFile
+	t.Cleanup(func() {
+		email, password, passwordStdin, passwordFile = oldEmail, oldPassword, oldStdin, oldFile
+	})
+
+	email, password, passwordStdin, passwordFile = e, p, stdin, file
+}
+
+// writeTestInput writes content to a file and opens it, as a stand in for a
+// piped stdin.
+func writeTestInput(t *testing.T, content string) *os.File {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "input")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	return file
+}
+
+func TestResolveCredentials(t *testing.T) {
+	passwordPath := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordPath, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		email       string
+		password    string
+		stdin       bool
+		file        string
+		envEmail    string
+		envPassword string
+		input       string
+		expected    edubase.Credentials
+	}{
+		{
+			name:     "flags",
+			email:    "flag@example.com",
+			password: "from-flag",
+			envEmail: "env@example.com", envPassword: "from-env",
+			expected: edubase.Credentials{Email: "flag@example.com", Password: "from-flag"},
+		},
+		{
+			name:     "environment",
+			envEmail: "env@example.com", envPassword: "from-env",
+			expected: edubase.Credentials{Email: "env@example.com", Password: "from-env"},
+		},
+		{
+			name:        "password stdin",
+			email:       "flag@example.com",
+			stdin:       true,
+			input:       "from-stdin\n",
+			envPassword: "from-env",
+			expected:    edubase.Credentials{Email: "flag@example.com", Password: "from-stdin"},
+		},
+		{
+			name:        "password file",
+			envEmail:    "env@example.com",
+			file:        passwordPath,
+			envPassword: "from-env",
+			expected:    edubase.Credentials{Email: "env@example.com", Password: "from-file"},
+		},
+		{
+			name:     "email prompt",
+			input:    "typed@example.com\n",
+			file:     passwordPath,
+			expected: edubase.Credentials{Email: "typed@example.com", Password: "from-file"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setCredentialFlags(t, tt.email, tt.password, tt.stdin, tt.file)
+			t.Setenv(emailEnv, tt.envEmail)
+			t.Setenv(passwordEnv, tt.envPassword)
+
+			credentials, err := resolveCredentials(writeTestInput(t, tt.input), io.Discard)
+			if err != nil {
+				t.Fatalf("resolve credentials failed: %v", err)
+			}
+			if credentials != tt.expected {
+				t.Errorf("got %+v, want %+v", credentials, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveCredentialsErrors(t *testing.T) {
+	t.Setenv(emailEnv, "")
+	t.Setenv(passwordEnv, "")
+
+	t.Run("password stdin without email", func(t *testing.T) {
+		setCredentialFlags(t, "", "", true, "")
+		if _, err := resolveCredentials(writeTestInput(t, "secret\n"), io.Discard); err == nil {
+			t.Error("expected error without email")
+		}
+	})
+
+	t.Run("missing password file", func(t *testing.T) {
+		setCredentialFlags(t, "flag@example.com", "", false, filepath.Join(t.TempDir(), "missing"))
+		if _, err := resolveCredentials(writeTestInput(t, ""), io.Discard); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("got error %v, want %v", err, os.ErrNotExist)
+		}
+	})
+
+	t.Run("password prompt without terminal", func(t *testing.T) {
+		setCredentialFlags(t, "flag@example.com", "", false, "")
+		if _, err := resolveCredentials(writeTestInput(t, "secret\n"), io.Discard); !errors.Is(err, edubase.ErrNotTerminal) {
+			t.Errorf("got error %v, want %v", err, edubase.ErrNotTerminal)
+		}
+	})
+}
+
+func TestCheckLoginMethod(t *testing.T) {
+	defer func(method string) { loginMethod = method }(loginMethod)
+
+	for _, method := range []string{"edubase", "microsoft"} {
+		loginMethod = method
+		if err := checkLoginMethod(); err != nil {
+			t.Errorf("login method %q: %v", method, err)
+		}
+	}
+
+	loginMethod = "google"
+	if err := checkLoginMethod(); err == nil {
+		t.Error("expected error for unknown login method")
+	}
+}
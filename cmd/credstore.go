@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/credstore"
+	"github.com/spf13/cobra"
+)
+
+const defaultProfile = "default"
+
+var showPassword bool = false
+
+func init() {
+	credentialsSetCmd.Flags().StringVarP(&email, "email", "e", "", "Edubase email to store. Defaults to $EDUBASE_EMAIL.")
+	credentialsSetCmd.Flags().StringVarP(&password, "password", "p", "", "Edubase password to store. It is visible to other users in the process list, prefer --password-stdin, --password-file or $EDUBASE_PASSWORD.")
+	credentialsSetCmd.Flags().BoolVar(&passwordStdin, "password-stdin", passwordStdin, "Read the password from the first line of stdin.")
+	credentialsSetCmd.Flags().StringVar(&passwordFile, "password-file", passwordFile, "Read the password from the first line of the file.")
+	credentialsSetCmd.MarkFlagsMutuallyExclusive("password", "password-stdin", "password-file")
+
+	credentialsGetCmd.Flags().BoolVar(&showPassword, "show-password", showPassword, "Print the password instead of hiding it.")
+
+	credentialsCmd.AddCommand(credentialsSetCmd)
+	credentialsCmd.AddCommand(credentialsGetCmd)
+	credentialsCmd.AddCommand(credentialsDeleteCmd)
+	rootCmd.AddCommand(credentialsCmd)
+}
+
+var credentialsCmd = &cobra.Command{
+	Use:   "credentials",
+	Short: "Manage credentials in an encrypted store",
+	Long: `Description:
+  The credentials command stores Edubase credentials by profile name in a file encrypted with a
+  passphrase (see --credentials-file). Use a profile with "import --profile" instead of passing
+  the password on the command line. The passphrase is asked for, or read from $EDUBASE_PASSPHRASE.
+
+Example:
+  edubase-to-pdf credentials set school -e your_email@example.com
+  edubase-to-pdf import --profile school
+
+  This example stores the credentials as profile "school" and imports a book with them.
+
+Contact:
+  For any issues or questions, please open an issue on the GitHub repository:
+  https://github.com/michaelbeutler/edubase-to-pdf/issues`,
+}
+
+var credentialsSetCmd = &cobra.Command{
+	Use:   "set [profile]",
+	Short: "Add or replace the credentials of a profile",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCredentialsSet(profileArg(args), os.Stdin, os.Stderr); err != nil {
+			log.Fatalf("%v", err)
+		}
+	},
+}
+
+var credentialsGetCmd = &cobra.Command{
+	Use:   "get [profile]",
+	Short: "Print the credentials of a profile",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCredentialsGet(profileArg(args), os.Stdin, os.Stdout, os.Stderr); err != nil {
+			log.Fatalf("%v", err)
+		}
+	},
+}
+
+var credentialsDeleteCmd = &cobra.Command{
+	Use:   "delete [profile]",
+	Short: "Delete the credentials of a profile",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCredentialsDelete(profileArg(args), os.Stdin, os.Stderr); err != nil {
+			log.Fatalf("%v", err)
+		}
+	},
+}
+
+// profileArg returns the profile given as argument, or the default profile.
+func profileArg(args []string) string {
+	if len(args) == 0 {
+		return defaultProfile
+	}
+	return args[0]
+}
+
+// runCredentialsSet stores the credentials of resolveCredentials as name.
+func runCredentialsSet(name string, stdin *os.File, prompt io.Writer) error {
+	credentials, err := resolveCredentials(stdin, prompt)
+	if err != nil {
+		return fmt.Errorf("could not get credentials: %w", err)
+	}
+
+	store, err := loadCredentialStore(stdin, prompt, true)
+	if err != nil {
+		return err
+	}
+
+	store.Set(name, credentials)
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(prompt, "Saved credentials of profile %q to %s\n", name, credentialsPath)
+	return nil
+}
+
+// runCredentialsGet prints the credentials of name to w.
+func runCredentialsGet(name string, stdin *os.File, w io.Writer, prompt io.Writer) error {
+	store, err := loadCredentialStore(stdin, prompt, false)
+	if err != nil {
+		return err
+	}
+
+	credentials, err := store.Get(name)
+	if errors.Is(err, credstore.ErrProfileNotFound) {
+		return fmt.Errorf("%w, profiles: %s", err, strings.Join(store.Profiles(), ", "))
+	}
+	if err != nil {
+		return err
+	}
+
+	shownPassword := strings.Repeat("*", 8)
+	if showPassword {
+		shownPassword = credentials.Password
+	}
+
+	fmt.Fprintf(w, "Profile:  %s\n", name)
+	fmt.Fprintf(w, "Email:    %s\n", credentials.Email)
+	fmt.Fprintf(w, "Password: %s\n", shownPassword)
+	return nil
+}
+
+// runCredentialsDelete removes name from the credential store.
+func runCredentialsDelete(name string, stdin *os.File, prompt io.Writer) error {
+	store, err := loadCredentialStore(stdin, prompt, false)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Delete(name); err != nil {
+		return err
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(prompt, "Deleted credentials of profile %q\n", name)
+	return nil
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/credstore"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+)
+
+// setCredentialStore points --credentials-file and --profile to a new store
+// in a temporary directory for the test.
+func setCredentialStore(t *testing.T, name string) {
+	t.Helper()
+
+	oldPath, oldProfile := credentialsPath, profile
+	t.Cleanup(func() { credentialsPath, profile = oldPath, oldProfile })
+
+	credentialsPath = filepath.Join(t.TempDir(), "credentials.json")
+	profile = name
+	t.Setenv(passphraseEnv, "passphrase")
+}
+
+func TestCredentialsCommands(t *testing.T) {
+	setCredentialStore(t, "")
+	setCredentialFlags(t, "", "", false, "")
+	t.Setenv(emailEnv, "student@example.com")
+	t.Setenv(passwordEnv, "s3cret")
+
+	if err := runCredentialsSet("school", writeTestInput(t, ""), io.Discard); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := runCredentialsGet("school", writeTestInput(t, ""), &out, io.Discard); err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "student@example.com") {
+		t.Errorf("output does not contain the email:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "s3cret") {
+		t.Errorf("output contains the password:\n%s", out.String())
+	}
+
+	if err := runCredentialsGet("other", writeTestInput(t, ""), io.Discard, io.Discard); !errors.Is(err, credstore.ErrProfileNotFound) {
+		t.Errorf("got error %v, want %v", err, credstore.ErrProfileNotFound)
+	}
+
+	if err := runCredentialsDelete("school", writeTestInput(t, ""), io.Discard); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if err := runCredentialsGet("school", writeTestInput(t, ""), io.Discard, io.Discard); !errors.Is(err, credstore.ErrProfileNotFound) {
+		t.Errorf("got error %v, want %v", err, credstore.ErrProfileNotFound)
+	}
+}
+
+func TestResolveCredentialsProfile(t *testing.T) {
+	setCredentialStore(t, "school")
+	setCredentialFlags(t, "", "", false, "")
+	t.Setenv(emailEnv, "env@example.com")
+	t.Setenv(passwordEnv, "from-env")
+
+	if _, err := resolveCredentials(writeTestInput(t, ""), io.Discard); err == nil {
+		t.Error("expected error without credential store")
+	}
+
+	store, err := credstore.Load(credentialsPath, []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := edubase.Credentials{Email: "student@example.com", Password: "s3cret"}
+	store.Set("school", expected)
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	credentials, err := resolveCredentials(writeTestInput(t, ""), io.Discard)
+	if err != nil {
+		t.Fatalf("resolve credentials failed: %v", err)
+	}
+	if credentials != expected {
+		t.Errorf("got %+v, want %+v", credentials, expected)
+	}
+
+	t.Setenv(passphraseEnv, "wrong")
+	if _, err := resolveCredentials(writeTestInput(t, ""), io.Discard); !errors.Is(err, credstore.ErrWrongPassphrase) {
+		t.Errorf("got error %v, want %v", err, credstore.ErrWrongPassphrase)
+	}
+}
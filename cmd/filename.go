@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxFilenameBytes is the longest file name ext4 and APFS accept. NTFS
+// allows 255 UTF-16 code units, which is never less. Long paths are no
+// concern, Go handles paths beyond 260 characters on Windows itself.
+const maxFilenameBytes = 255
+
+// reservedFilenames are device names that Windows does not allow as file
+// names, not even with an extension like "CON.pdf".
+var reservedFilenames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"COM¹": true, "COM²": true, "COM³": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+	"LPT¹": true, "LPT²": true, "LPT³": true,
+	"CONIN$": true, "CONOUT$": true,
+}
+
+// sanitizeFilename makes filename a valid file name on Linux, macOS and
+// Windows. It is composed to NFC, so a title gives the same name whatever
+// form it came in. Path separators, characters Windows reserves and control
+// characters are replaced by "_", and line breaks and tabs by spaces.
+// Leading spaces and trailing dots and spaces, which Windows drops, are
+// removed, reserved device names like "CON" get a "_" and long names are cut
+// to maxFilenameBytes. A name that consists of nothing else becomes "_", an
+// empty one stays empty.
+func sanitizeFilename(filename string) string {
+	if filename == "" {
+		return ""
+	}
+
+	name := norm.NFC.String(strings.ToValidUTF8(filename, "_"))
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			return ' '
+		case unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r):
+			return '_'
+		}
+		return r
+	}, name)
+
+	name = limitFilename(name, "")
+	if stem, _, _ := strings.Cut(name, "."); reservedFilenames[strings.ToUpper(strings.TrimRight(stem, " "))] {
+		name = limitFilename(stem+"_", name[len(stem):])
+	}
+
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+// limitFilename returns stem followed by suffix, cutting stem so that the
+// name fits into maxFilenameBytes. Spaces and dots the name starts or ends
+// with are removed like on Windows.
+func limitFilename(stem string, suffix string) string {
+	stem = strings.TrimLeft(stem, " ")
+
+	limit := maxFilenameBytes - len(suffix)
+	if len(stem) > limit {
+		for limit > 0 && !utf8.RuneStart(stem[limit]) {
+			limit--
+		}
+		stem = stem[:limit]
+	}
+
+	return strings.TrimRight(stem+suffix, ". ")
+}
+
+// outputNames hands out the paths of the PDFs of a session, so that books
+// with the same name do not overwrite each other or the PDFs of earlier
+// runs.
+type outputNames struct {
+	// books maps the key of a path to the ID of the book it was given to
+	books map[string]int
+}
+
+func newOutputNames() *outputNames {
+	return &outputNames{books: map[string]int{}}
+}
+
+// claim returns path for the book with the given ID. If path was already
+// given to another book, or there is the PDF of another book from an earlier
+// run, " (2)", " (3)" and so on are added to its name until it is unique.
+// Other files at path, whose book is unknown, are replaced. Books get the
+// same paths again if they are claimed in the same order, and a book
+// claiming its path again gets the same one.
+func (n *outputNames) claim(path string, bookId int) string {
+	dir, base := filepath.Split(path)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	for i := 1; ; i++ {
+		candidate := path
+		if i > 1 {
+			candidate = filepath.Join(dir, limitFilename(stem, fmt.Sprintf(" (%d)%s", i, ext)))
+		}
+
+		key := outputNameKey(candidate)
+		id, ok := n.books[key]
+		if ok && id == bookId {
+			return candidate
+		}
+		if !ok && !isOtherBook(candidate, bookId) {
+			n.books[key] = bookId
+			return candidate
+		}
+	}
+}
+
+// isOtherBook reports whether the file at path is the PDF of another book
+// than the one with the given ID, e.g. one with the same title from an
+// earlier run.
+func isOtherBook(path string, bookId int) bool {
+	id, ok := pdfBookId(path)
+	return ok && id != bookId
+}
+
+// outputNameKey returns the key of path that paths to the same file share.
+// Windows and macOS ignore case and macOS the Unicode normalization form,
+// so paths that only differ in these are the same file there.
+func outputNameKey(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return cases.Fold().String(norm.NFC.String(path))
+}
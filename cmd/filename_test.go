@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"normal_filename", "normal_filename"},
+		{"file/with/slash", "file_with_slash"},
+		{"file\\with\\backslash", "file_with_backslash"},
+		{"file:with:colon", "file_with_colon"},
+		{"file*with*asterisk", "file_with_asterisk"},
+		{"file?with?question", "file_with_question"},
+		{"file\"with\"quote", "file_with_quote"},
+		{"file<with<less", "file_with_less"},
+		{"file>with>greater", "file_with_greater"},
+		{"file|with|pipe", "file_with_pipe"},
+		{"all/\\:*?\"<>|chars", "all_________chars"},
+		{"", ""},
+		{"Mathematik\n1", "Mathematik 1"},
+		{"bell\aand\x00null", "bell_and_null"},
+		{"invalid \xff utf-8", "invalid _ utf-8"},
+		{"Mathe\u0301matik", "Mathématik"},
+		{"  Deutsch. . ", "Deutsch"},
+		{"...", "_"},
+		{"CON", "CON_"},
+		{"nul.pdf", "nul_.pdf"},
+		{"Com1 .txt", "Com1 _.txt"},
+		{"LPT¹", "LPT¹_"},
+		{"CONIN$", "CONIN$_"},
+		{"conout$.pdf", "conout$_.pdf"},
+		{"CONIN$ 2", "CONIN$ 2"},
+		{"CONTENT", "CONTENT"},
+		{"Console.pdf", "Console.pdf"},
+		{strings.Repeat("a", 300), strings.Repeat("a", maxFilenameBytes)},
+		{strings.Repeat("ä", 200), strings.Repeat("ä", maxFilenameBytes/2)},
+	}
+
+	for _, tt := range tests {
+		result := sanitizeFilename(tt.input)
+		if result != tt.expected {
+			t.Errorf("sanitizeFilename(%q) = %q; want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestLimitFilename(t *testing.T) {
+	tests := []struct {
+		stem   string
+		suffix string
+		want   string
+	}{
+		{"book", ".pdf", "book.pdf"},
+		{strings.Repeat("a", 300), ".pdf", strings.Repeat("a", maxFilenameBytes-4) + ".pdf"},
+		{strings.Repeat("a", 250) + "   b", " (2).pdf", strings.Repeat("a", 247) + " (2).pdf"},
+		{strings.Repeat("ü", 200), ".pdf", strings.Repeat("ü", 125) + ".pdf"},
+		{"  book.", "", "book"},
+	}
+
+	for _, tt := range tests {
+		got := limitFilename(tt.stem, tt.suffix)
+		if got != tt.want {
+			t.Errorf("limitFilename(%q, %q) = %q; want %q", tt.stem, tt.suffix, got, tt.want)
+		}
+		if len(got) > maxFilenameBytes {
+			t.Errorf("limitFilename(%q, %q) is %d bytes long", tt.stem, tt.suffix, len(got))
+		}
+	}
+}
+
+func TestOutputNamesClaim(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Mathematik.pdf")
+
+	names := newOutputNames()
+	claims := []struct {
+		path   string
+		bookId int
+		want   string
+	}{
+		{path, 1, path},
+		{path, 2, filepath.Join(dir, "Mathematik (2).pdf")},
+		// paths differing in case are the same file on Windows and macOS
+		{filepath.Join(dir, "MATHEMATIK.pdf"), 3, filepath.Join(dir, "MATHEMATIK (3).pdf")},
+		// a book keeps its path
+		{path, 1, path},
+		{path, 2, filepath.Join(dir, "Mathematik (2).pdf")},
+		{filepath.Join(dir, "Deutsch.pdf"), 4, filepath.Join(dir, "Deutsch.pdf")},
+	}
+
+	for _, c := range claims {
+		if got := names.claim(c.path, c.bookId); got != c.want {
+			t.Errorf("claim(%q, %d) = %q; want %q", c.path, c.bookId, got, c.want)
+		}
+	}
+}
+
+func TestOutputNamesClaimExisting(t *testing.T) {
+	dir := t.TempDir()
+
+	image := filepath.Join(dir, "1.jpeg")
+	writeTestJPEG(t, image, 119, 168)
+
+	// the PDF of book 1 from an earlier run
+	path := filepath.Join(dir, "Mathematik.pdf")
+	metadata := bookMetadata(edubase.Book{Id: 1, Title: "Mathematik", ISBN: "978-3-03713-123-4"})
+	if err := generateRasterPDF(context.Background(), []string{image}, nil, nil, nil, metadata, path); err != nil {
+		t.Fatalf("could not create pdf: %v", err)
+	}
+
+	other := filepath.Join(dir, "Deutsch.pdf")
+	if err := os.WriteFile(other, []byte("not a PDF of this tool"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	claims := []struct {
+		path   string
+		bookId int
+		want   string
+	}{
+		// the same book replaces its PDF, another one gets its own
+		{path, 1, path},
+		{path, 2, filepath.Join(dir, "Mathematik (2).pdf")},
+		// files of unknown books are replaced
+		{other, 3, other},
+	}
+
+	for _, c := range claims {
+		if got := newOutputNames().claim(c.path, c.bookId); got != c.want {
+			t.Errorf("claim(%q, %d) = %q; want %q", c.path, c.bookId, got, c.want)
+		}
+	}
+}
+
+func TestBookOutputFile(t *testing.T) {
+	defer func(path string) { outputPath = path }(outputPath)
+
+	dir := t.TempDir()
+
+	image := filepath.Join(dir, "1.jpeg")
+	writeTestJPEG(t, image, 119, 168)
+
+	// the PDF of book 1 from an earlier run
+	path := filepath.Join(dir, "Mathematik.pdf")
+	metadata := bookMetadata(edubase.Book{Id: 1, Title: "Mathematik"})
+	if err := generateRasterPDF(context.Background(), []string{image}, nil, nil, nil, metadata, path); err != nil {
+		t.Fatalf("could not create pdf: %v", err)
+	}
+
+	book := edubase.Book{Id: 2, Title: "Mathematik"}
+	tests := []struct {
+		output string
+		want   string
+	}{
+		// named by the title, the PDF of book 1 is kept
+		{dir, filepath.Join(dir, "Mathematik (2).pdf")},
+		{filepath.Join(dir, "{{.Title}}.pdf"), filepath.Join(dir, "Mathematik (2).pdf")},
+		// named explicitly, the PDF is written as given
+		{path, path},
+	}
+
+	for _, tt := range tests {
+		outputPath = tt.output
+		i := &importProcess{outputNames: newOutputNames()}
+
+		got, err := i.bookOutputFile(book)
+		if err != nil {
+			t.Fatalf("bookOutputFile with output %q failed: %v", tt.output, err)
+		}
+		if got != tt.want {
+			t.Errorf("bookOutputFile with output %q = %q; want %q", tt.output, got, tt.want)
+		}
+	}
+}
@@ -1,17 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
-	"strings"
+	"sync"
 	"time"
 
-	"github.com/charmbracelet/huh"
-	"github.com/charmbracelet/huh/spinner"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/atomicfile"
 	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
-	pdfcpu "github.com/pdfcpu/pdfcpu/pkg/api"
-	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"github.com/playwright-community/playwright-go"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
@@ -29,22 +27,47 @@ var width int = 2560
 var height int = 1440
 var pageDelay time.Duration = 500 * time.Millisecond
 var timeout time.Duration = 5 * time.Minute
+var captureMode string = captureModeScreenshot
+var textLayer bool = true
+var workers int = 1
+var bookIds []int
+var allBooks bool = false
+var bookTitle string = ""
 
 func init() {
 	importCmd.Flags().StringVarP(&screenshotDir, "temp", "t", "screenshots", "Temporary directory for screenshots these will be used to generate the pdf.")
-	importCmd.Flags().StringVarP(&email, "email", "e", "", "Edubase email for login.")
-	importCmd.Flags().StringVarP(&password, "password", "p", "", "Edubase password for login.")
+	importCmd.Flags().StringVarP(&email, "email", "e", "", "Edubase email for login. Defaults to $EDUBASE_EMAIL.")
+	importCmd.Flags().StringVarP(&password, "password", "p", "", "Edubase password for login. It is visible to other users in the process list, prefer --password-stdin, --password-file or $EDUBASE_PASSWORD.")
+	importCmd.Flags().BoolVar(&passwordStdin, "password-stdin", passwordStdin, "Read the password from the first line of stdin.")
+	importCmd.Flags().StringVar(&passwordFile, "password-file", passwordFile, "Read the password from the first line of the file.")
+	importCmd.Flags().StringVar(&profile, "profile", profile, "Read the credentials from this profile of the credential store, see the credentials command.")
 	importCmd.Flags().IntVarP(&maxPages, "max-pages", "m", -1, "Max pages to import from the book.")
 	importCmd.Flags().IntVarP(&startPage, "start-page", "s", 1, "Start page to import from the book.")
+	importCmd.Flags().StringVar(&pagesExpr, "pages", pagesExpr, "Pages to import, e.g. \"1-10,15,40-\" for pages 1 to 10, page 15 and page 40 to the end. Replaces --start-page and --max-pages.")
 	importCmd.Flags().BoolVarP(&imgOverwrite, "img-overwrite", "o", false, "Overwrite existing screenshots.")
 	importCmd.Flags().BoolVarP(&debug, "debug", "d", false, "Debug mode. Show browser window.")
 	importCmd.Flags().BoolVarP(&manualLogin, "manual", "M", false, "Type your credentials manually. This is useful if you use Microsoft login.")
+	importCmd.Flags().StringVar(&loginMethod, "login-method", loginMethod, "How to log in with the credentials: \"edubase\" or \"microsoft\" for Microsoft accounts of schools.")
 	importCmd.Flags().IntVarP(&height, "height", "H", height, "Browser height in pixels this can affect the screenshot quality.")
 	importCmd.Flags().IntVarP(&width, "width", "W", width, "Browser width in pixels this can affect the screenshot quality.")
 	importCmd.Flags().DurationVarP(&pageDelay, "page-delay", "D", pageDelay, "Delay between pages in milliseconds. This is required to give the browser time to load the page.")
-	importCmd.Flags().DurationVarP(&timeout, "timeout", "T", timeout, "Maximum time the app can take to download all pages. (increase this value for large books)")
+	importCmd.Flags().DurationVarP(&timeout, "timeout", "T", timeout, "Maximum time the app can take to download all pages of a book. (increase this value for large books)")
+	importCmd.Flags().StringVarP(&captureMode, "capture", "c", captureMode, "How pages are captured: \"screenshot\" takes JPEG screenshots, \"svg\" extracts the page SVG and creates a vector PDF with crisp, selectable text.")
 
-	importCmd.MarkFlagsRequiredTogether("email", "password")
+	importCmd.Flags().BoolVar(&textLayer, "text-layer", textLayer, "Add an invisible text layer to screenshot PDFs so they can be searched and copied from.")
+	importCmd.Flags().IntVarP(&workers, "workers", "w", workers, "Number of browser pages that capture pages of the book at the same time.")
+
+	importCmd.Flags().IntSliceVar(&bookIds, "book-id", bookIds, "ID of a book to import, repeat it to import several books. Skips the book selection.")
+	importCmd.Flags().StringVar(&bookTitle, "book-title", bookTitle, "Title of the book to import, or words of it. Skips the book selection.")
+	importCmd.Flags().BoolVar(&allBooks, "all", allBooks, "Import every book of the library.")
+	importCmd.Flags().StringVar(&outputPath, "output", outputPath, "Directory to save the PDFs in, the path of the PDF if a single book is imported, or a template of their path like \"pdfs/{{.Title}} ({{.Id}}).pdf\". Templates can use .Id, .Title, .Version, .Authors, .Publisher and .ISBN. Defaults to \"{{.Title}}.pdf\" in the current directory.")
+
+	importCmd.MarkFlagsMutuallyExclusive("manual", "login-method")
+	importCmd.MarkFlagsMutuallyExclusive("profile", "email")
+	importCmd.MarkFlagsMutuallyExclusive("profile", "password", "password-stdin", "password-file")
+	importCmd.MarkFlagsMutuallyExclusive("book-id", "book-title", "all")
+	importCmd.MarkFlagsMutuallyExclusive("pages", "start-page")
+	importCmd.MarkFlagsMutuallyExclusive("pages", "max-pages")
 
 	rootCmd.AddCommand(importCmd)
 }
@@ -53,7 +76,8 @@ var importCmd = &cobra.Command{
 	Use: "import",
 	Long: `Description:
   The import command will sign in to Edubase, fetch the books, and take screenshots of the pages. 
-  Screenshots will be used to generate a PDF. The PDF will be saved in the current directory.
+  Screenshots will be used to generate a PDF. The PDF will be saved in the current directory,
+  or as given by --output.
 
 Example:
   edubase-to-pdf import -e your_email@example.com -p your_password -s 2 -m 10
@@ -62,148 +86,262 @@ Example:
   It will start importing from page 2 and import a maximum of 10 pages. 
   The resulting PDF will be saved in the current directory.
 
+  edubase-to-pdf import -e your_email@example.com -p your_password --pages 1-10,15,40-
+
+  This example imports pages 1 to 10, page 15 and every page from page 40 to the end of the book
+  into one PDF.
+
+  edubase-to-pdf import -e your_email@example.com -p your_password --book-title "mathematik 1"
+
+  This example imports the book titled "Mathematik 1" without asking which book to import,
+  e.g. from a script or cron job.
+
+  edubase-to-pdf import -e your_email@example.com -p your_password --book-id 58216 --book-id 61532
+
+  This example imports two books in one session and reports the result of each book at the end.
+  Use --all to import every book of your library.
+
+  edubase-to-pdf import -e your_email@example.com -p your_password --all --output "books/{{.Title}} ({{.Id}}).pdf"
+
+  This example saves every book of your library in the directory "books", named by title and ID.
+
 Contact:
   For any issues or questions, please open an issue on the GitHub repository:
   https://github.com/michaelbeutler/edubase-to-pdf/issues`,
 	Run: func(cmd *cobra.Command, args []string) {
-		err := playwright.Install()
-		if err != nil {
-			log.Fatalf("could not install Playwright: %v", err)
+		if err := checkLoginMethod(); err != nil {
+			log.Fatalf("%v", err)
 		}
 
-		importProcess := newImportProcess()
-
-		credentials := edubase.Credentials{
-			Email:    email,
-			Password: password,
+		if captureMode != captureModeScreenshot && captureMode != captureModeSVG {
+			log.Fatalf("invalid capture mode %q: must be %q or %q", captureMode, captureModeScreenshot, captureModeSVG)
 		}
 
-		// if email or password is empty, get credentials from form
-		if manualLogin {
-			fmt.Println("Manual login selected. Please complete the login in the opened browser window...")
-			fmt.Println("For closing the application, close the browser window and press Ctrl+C in this terminal...")
-		} else {
-			if email == "" || password == "" {
-				c, err := edubase.GetCredentials()
-				if err != nil {
-					log.Fatalf("could not get credentials: %v", err)
-				}
+		if _, _, err := parseOutput(outputPath); err != nil {
+			log.Fatalf("%v", err)
+		}
+		if err := checkOutputBooks(outputPath, len(bookIds)); err != nil {
+			log.Fatalf("%v", err)
+		}
 
-				credentials = c
-			}
+		if _, err := importPageRanges(0); err != nil {
+			log.Fatalf("%v", err)
 		}
 
-		// login
-		importProcess.login(credentials)
+		if workers < 1 {
+			log.Fatalf("invalid number of workers %d: must be at least 1", workers)
+		}
 
-		// get books
-		books, err := importProcess.getBooks()
+		err := playwright.Install()
 		if err != nil {
-			log.Fatalf("could not get books: %v", err)
+			log.Fatalf("could not install Playwright: %v", err)
 		}
 
-		book := edubase.Book{}
-		booksForm := huh.NewForm(
-			huh.NewGroup(
-				huh.NewSelect[edubase.Book]().Title("Book").OptionsFunc(func() []huh.Option[edubase.Book] {
-					return huh.NewOptions(books...)
-				}, &books).Key("Title").Value(&book),
-			),
-		)
+		ctx, cancel := newCommandContext(cmd.Context())
+		defer cancel()
 
-		err = booksForm.Run()
-		if err != nil {
-			log.Fatalf("could not get book id: %v", err)
+		if err := runImport(ctx, useSession(cmd.Flags())); err != nil {
+			exitWithError(err)
 		}
+	},
+}
 
-		// open book
-		importProcess.bookProvider = edubase.NewBookProvider(importProcess.page, book.Id)
+// runImport signs in, lets the user pick a book and imports it as PDF. The
+// browser is closed as soon as ctx is done, --timeout applies to each book
+// once it is selected. With loadSession, the session file is used instead of
+// logging in.
+func runImport(ctx context.Context, loadSession bool) error {
+	importProcess, err := newImportProcess(loadSession)
+	if err != nil {
+		return err
+	}
+	defer importProcess.close()
 
-		err = importProcess.bookProvider.Open(startPage)
+	if captureMode == captureModeSVG {
+		importProcess.renderer, err = newSVGRenderer(importProcess.pw)
 		if err != nil {
-			log.Fatalf("could not open book: %v", err)
+			return err
 		}
+	}
 
-		totalPages, err := importProcess.bookProvider.GetTotalPages()
-		if err != nil {
-			log.Fatalf("could not get total pages: %v", err)
-		}
+	defer importProcess.closeWhenDone(ctx)()
+
+	if err := importProcess.authenticate(ctx); err != nil {
+		return err
+	}
+
+	// get books
+	books, err := importProcess.getBooks(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get books: %w", err)
+	}
+
+	selected, missing, err := selectBooks(ctx, books)
+	if err != nil {
+		return err
+	}
+	if err := checkOutputBooks(outputPath, len(selected)+len(missing)); err != nil {
+		return err
+	}
+	for _, result := range missing {
+		log.Printf("could not import book %d: %v", result.book.Id, result.err)
+	}
 
-		if maxPages != -1 {
-			totalPages = maxPages
+	// a single book fails like it always did, batches report every book
+	if len(selected) == 1 && len(missing) == 0 {
+		if _, err := importProcess.importBookWithTimeout(ctx, selected[0]); err != nil {
+			return err
 		}
+		return importProcess.close()
+	}
 
-		createDirIfNotExists(screenshotDir)
+	results := append(missing, importProcess.importBooks(ctx, selected)...)
+	printImportReport(os.Stdout, results)
 
-		barDownloadImg := progressbar.Default(int64(totalPages), "Downloading pages...")
-		for i := startPage; i <= (startPage-1)+totalPages; i++ {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if failed := countFailed(results); failed > 0 {
+		return fmt.Errorf("could not import %d of %d books", failed, len(results))
+	}
 
-			filename := fmt.Sprintf("%s/%d_%d.jpeg", screenshotDir, book.Id, i)
+	return importProcess.close()
+}
 
-			if _, err := os.Stat(filename); err == nil && !imgOverwrite {
-				// file exists, skip screenshot
-			} else {
-				// wait for page to load
-				time.Sleep(pageDelay)
-				// take screenshot
-				err = importProcess.bookProvider.Screenshot(filename)
-				if err != nil {
-					log.Fatalf("could not take screenshot: %v", err)
-				}
-			}
+// bookOutputFile returns the path of the PDF of book. A PDF named by --output
+// is written as given, other paths are claimed so that the PDFs of other
+// books are not replaced.
+func (i *importProcess) bookOutputFile(book edubase.Book) (string, error) {
+	pdfPath, err := outputFile(outputPath, book)
+	if err != nil || outputIsFile(outputPath) {
+		return pdfPath, err
+	}
 
-			// next page
-			err = importProcess.bookProvider.NextPage()
-			if err != nil {
-				log.Fatalf("could not navigate to next page: %v", err)
-			}
+	if path := i.outputNames.claim(pdfPath, book.Id); path != pdfPath {
+		log.Printf("%s is the PDF of another book, saving book %d as %s", pdfPath, book.Id, path)
+		pdfPath = path
+	}
+	return pdfPath, nil
+}
 
-			barDownloadImg.Add(1)
-		}
+// importBook imports book into a PDF at the path given by --output and returns
+// the path of the PDF.
+func (i *importProcess) importBook(ctx context.Context, book edubase.Book) (string, error) {
+	// a broken output template should not cost a whole capture
+	pdfPath, err := i.bookOutputFile(book)
+	if err != nil {
+		return "", err
+	}
 
-		// Generate PDF from screenshots that are previously taken
-		barImgtoPdf := progressbar.Default(int64(totalPages), "Generating PDF...")
-		pdfPath := fmt.Sprintf("%s.pdf", sanitizeFilename(book.Title))
-		for i := startPage; i <= (startPage-1)+totalPages; i++ {
+	ranges, err := importPageRanges(0)
+	if err != nil {
+		return "", err
+	}
 
-			filename := fmt.Sprintf("%s/%d_%d.jpeg", screenshotDir, book.Id, i)
-			// Generate PDF and append
-			pdfcpu.ImportImagesFile([]string{filename}, fmt.Sprintf("%s.pdf", book.Title), nil, model.NewDefaultConfiguration())
-			time.Sleep(pageDelay)
-			barImgtoPdf.Add(1)
-		}
+	// open book
+	i.bookProvider = edubase.NewBookProvider(i.page, book.Id, providerOptions()...)
 
-		// Read the PDF Syntax
-		pdfReadCtx, err := pdfcpu.ReadContextFile(pdfPath)
-		if err != nil {
-			log.Fatalf("❌ Failed to read PDF file '%s' to validate: %v", pdfPath, err)
-		}
-		// Validate the number of pages in the PDF
-		actualPageCountInPdf := pdfReadCtx.PageCount
-		if actualPageCountInPdf < totalPages {
-			log.Fatalf("❌ Failed to import all pages! Ebook Pages: %d | Pages in PDF: %d. Maybe delete PDF and try again.", totalPages, actualPageCountInPdf)
-		}
+	err = i.bookProvider.OpenContext(ctx, firstPage(ranges))
+	if err != nil {
+		return "", fmt.Errorf("could not open book: %w", err)
+	}
+
+	totalPages, err := i.bookProvider.GetTotalPagesContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not get total pages: %w", err)
+	}
+
+	// --start-page and --max-pages are cut off at the end of the book
+	ranges, err = importPageRanges(totalPages)
+	if err != nil {
+		return "", err
+	}
+	pageNumbers, err := selectPages(ranges, totalPages)
+	if err != nil {
+		return "", err
+	}
 
-		if actualPageCountInPdf > totalPages {
-			log.Fatalf("❌ PDF has too many pages! Ebook Pages: %d | Pages in PDF: %d. Maybe delete PDF and try again.", totalPages, actualPageCountInPdf)
+	// a missing table of contents only costs the bookmarks, not the import
+	toc, err := i.bookProvider.GetTableOfContentsContext(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", err
 		}
+		log.Printf("could not get table of contents, the PDF will have no bookmarks: %v", err)
+	}
+
+	createDirIfNotExists(screenshotDir)
+
+	extension := "jpeg"
+	if captureMode == captureModeSVG {
+		extension = "svg"
+	}
+
+	// the manifest tells which pages of an earlier import can be reused
+	bookManifestPath := manifestPath(screenshotDir, book.Id)
+	bookManifest, err := loadManifest(bookManifestPath, book.Id)
+	if err != nil {
+		log.Printf("%v, capturing all pages again", err)
+		bookManifest = newManifest(book.Id)
+	}
+	settings := captureSettings{
+		Mode:   captureMode,
+		Width:  width,
+		Height: height,
+	}
 
-		if err = importProcess.browser.Close(); err != nil {
-			log.Fatalf("could not close browser: %v", err)
+	pages := []bookPage{}
+	pageFiles := []string{}
+	textFiles := []string{}
+	for _, n := range pageNumbers {
+		page := bookPage{
+			number: n,
+			file:   fmt.Sprintf("%s/%d_%d.%s", screenshotDir, book.Id, n, extension),
 		}
-		if err = importProcess.pw.Stop(); err != nil {
-			log.Fatalf("could not stop Playwright: %v", err)
+		pageFiles = append(pageFiles, page.file)
+
+		// screenshots lose the text, keep it for the text layer of the PDF
+		if captureMode == captureModeScreenshot && textLayer {
+			page.textFile = fmt.Sprintf("%s/%d_%d.json", screenshotDir, book.Id, n)
+			textFiles = append(textFiles, page.textFile)
 		}
 
-	},
-}
+		pages = append(pages, page)
+	}
 
-func sanitizeFilename(filename string) string {
-	sanitized := filename
-	for _, char := range []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|"} {
-		sanitized = strings.ReplaceAll(sanitized, char, "_")
+	capturer := &pageCapturer{
+		manifest:     bookManifest,
+		manifestPath: bookManifestPath,
+		settings:     settings,
+		bar:          progressbar.Default(int64(len(pages)), "Downloading pages..."),
+	}
+	if err := i.capturePages(ctx, book.Id, pages, workers, capturer); err != nil {
+		return "", err
 	}
-	return sanitized
+
+	bookmarks := bookmarksFromTOC(toc, pageNumbers)
+	labels := pageLabels(bookManifest, pages)
+
+	// the PDF only appears under its name once it is complete
+	err = atomicfile.Write(pdfPath, 0644, func(tmpPath string) error {
+		if captureMode == captureModeSVG {
+			if err := generateVectorPDF(ctx, i.renderer, pageFiles, bookmarks, labels, bookMetadata(book), tmpPath); err != nil {
+				return err
+			}
+		} else {
+			// Generate PDF from screenshots that are previously taken
+			if err := generateRasterPDF(ctx, pageFiles, textFiles, bookmarks, labels, bookMetadata(book), tmpPath); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return pdfPath, nil
 }
 
 type importProcess struct {
@@ -213,12 +351,23 @@ type importProcess struct {
 	loginProvider   *edubase.LoginProvider
 	bookProvider    *edubase.BookProvider
 	libraryProvider *edubase.LibraryProvider
+	renderer        *svgRenderer
+	// outputNames keeps books of the session from getting the same PDF.
+	outputNames *outputNames
+	// sessionLoaded is set if the browser context was created from the
+	// session file.
+	sessionLoaded bool
+
+	closeOnce sync.Once
+	closeErr  error
 }
 
-func newPlaywrightPage() (playwright.Page, playwright.Browser, *playwright.Playwright) {
+// newPlaywrightPage launches Chromium and opens a page, logged in with the
+// session file if loadSession is set.
+func newPlaywrightPage(loadSession bool) (playwright.Page, playwright.Browser, *playwright.Playwright, error) {
 	pw, err := playwright.Run()
 	if err != nil {
-		log.Fatalf("failed to start Playwright: %v\nIf you're running in Docker or a minimal Linux environment, make sure required system libraries are installed (e.g., libglib2.0-0, libnss3, libnspr4, libdbus-1-3, libatk1.0-0, libatk-bridge2.0-0, libcups2, libdrm2, libatspi2.0-0, libx11-6, libxcomposite1, libxdamage1, libxext6, libxfixes3, libxrandr2, libgbm1, libxcb1, libxkbcommon0, libpango-1.0-0, libcairo2, libasound2).", err)
+		return nil, nil, nil, fmt.Errorf("failed to start Playwright: %w\nIf you're running in Docker or a minimal Linux environment, make sure required system libraries are installed (e.g., libglib2.0-0, libnss3, libnspr4, libdbus-1-3, libatk1.0-0, libatk-bridge2.0-0, libcups2, libdrm2, libatspi2.0-0, libx11-6, libxcomposite1, libxdamage1, libxext6, libxfixes3, libxrandr2, libgbm1, libxcb1, libxkbcommon0, libpango-1.0-0, libcairo2, libasound2).", err)
 	}
 
 	launchOptions := playwright.BrowserTypeLaunchOptions{
@@ -235,29 +384,47 @@ func newPlaywrightPage() (playwright.Page, playwright.Browser, *playwright.Playw
 	if err != nil {
 		// best effort cleanup
 		_ = pw.Stop()
-		log.Fatalf("failed to launch Chromium: %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to launch Chromium: %w", err)
 	}
 
-	page, err := browser.NewPage(playwright.BrowserNewPageOptions{
+	// pages of the same context share the login, which parallel capture needs
+	contextOptions := playwright.BrowserNewContextOptions{
 		Viewport: &playwright.Size{
 			Width:  *playwright.Int(width),
 			Height: *playwright.Int(height),
 		},
-	})
+	}
+	if loadSession {
+		contextOptions.StorageStatePath = playwright.String(sessionPath)
+	}
+
+	browserContext, err := browser.NewContext(contextOptions)
+	if err != nil {
+		_ = browser.Close()
+		_ = pw.Stop()
+		return nil, nil, nil, fmt.Errorf("failed to create browser context: %w", err)
+	}
+
+	page, err := browserContext.NewPage()
 	if err != nil {
 		_ = browser.Close()
 		_ = pw.Stop()
-		log.Fatalf("failed to create browser page: %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to create browser page: %w", err)
 	}
 
-	return page, browser, pw
+	return page, browser, pw, nil
 }
 
-func newImportProcess() *importProcess {
-	page, browser, pw := newPlaywrightPage()
+// newImportProcess starts the browser, with the session of the session file
+// if loadSession is set.
+func newImportProcess(loadSession bool) (*importProcess, error) {
+	page, browser, pw, err := newPlaywrightPage(loadSession)
+	if err != nil {
+		return nil, err
+	}
 
-	loginProvider := edubase.NewLoginProvider(page)
-	libraryProvider := edubase.NewLibraryProvider(page)
+	loginProvider := edubase.NewLoginProvider(page, providerOptions()...)
+	libraryProvider := edubase.NewLibraryProvider(page, providerOptions()...)
 
 	return &importProcess{
 		page:            page,
@@ -265,39 +432,138 @@ func newImportProcess() *importProcess {
 		pw:              pw,
 		loginProvider:   loginProvider,
 		libraryProvider: libraryProvider,
+		sessionLoaded:   loadSession,
+		outputNames:     newOutputNames(),
+	}, nil
+}
+
+// close closes the browser and stops Playwright. It is safe to call close
+// more than once, e.g. from a cancellation callback and a deferred cleanup.
+func (i *importProcess) close() error {
+	i.closeOnce.Do(func() {
+		if i.renderer != nil {
+			if err := i.renderer.close(); err != nil {
+				i.closeErr = fmt.Errorf("could not close renderer: %w", err)
+			}
+		}
+		if err := i.browser.Close(); err != nil && i.closeErr == nil {
+			i.closeErr = fmt.Errorf("could not close browser: %w", err)
+		}
+		if err := i.pw.Stop(); err != nil && i.closeErr == nil {
+			i.closeErr = fmt.Errorf("could not stop Playwright: %w", err)
+		}
+	})
+	return i.closeErr
+}
+
+// resetPages closes the pages of the browser context, which ends calls
+// still pending on them, and continues with a new page. The login belongs
+// to the browser context and is kept.
+func (i *importProcess) resetPages() error {
+	browserContext := i.page.Context()
+	page, err := browserContext.NewPage()
+	if err != nil {
+		return fmt.Errorf("could not open browser page: %w", err)
 	}
+
+	for _, p := range browserContext.Pages() {
+		if p == page {
+			continue
+		}
+		if err := p.Close(); err != nil {
+			return fmt.Errorf("could not close browser page: %w", err)
+		}
+	}
+
+	i.page = page
+	i.loginProvider = edubase.NewLoginProvider(page, providerOptions()...)
+	i.libraryProvider = edubase.NewLibraryProvider(page, providerOptions()...)
+	i.bookProvider = nil
+
+	if i.renderer != nil {
+		return i.renderer.reset()
+	}
+	return nil
 }
 
-func (i *importProcess) login(credentials edubase.Credentials) {
-	loginSpinner := "logging in..."
-	if (credentials.Email == "" || credentials.Password == "") && !manualLogin {
-		loginSpinner = "login manually in open browser..."
+// closeWhenDone closes the browser once ctx is done, which makes pending
+// playwright calls return immediately. The returned function stops it.
+func (i *importProcess) closeWhenDone(ctx context.Context) func() bool {
+	return context.AfterFunc(ctx, func() {
+		i.close()
+	})
+}
+
+// authenticate reuses the saved session if it is still valid, and logs in
+// otherwise.
+func (i *importProcess) authenticate(ctx context.Context) error {
+	if i.sessionLoaded {
+		loggedIn, err := i.loginProvider.IsLoggedInContext(ctx)
+		if err != nil && ctx.Err() != nil {
+			return err
+		}
+		if loggedIn {
+			return nil
+		}
+		log.Printf("session %s has expired, logging in again", sessionPath)
 	}
 
-	err := spinner.New().Title(loginSpinner).
-		Action(func() {
-			err := i.loginProvider.Login(credentials, manualLogin)
-			if err != nil {
-				log.Fatalf("could not login: %v", err)
-			}
+	if err := i.freshLogin(ctx); err != nil {
+		return err
+	}
 
-		}).
-		Run()
+	// keep the session of users who use one up to date
+	if i.sessionLoaded {
+		if err := i.loginProvider.SaveSessionContext(ctx, sessionPath); err != nil {
+			log.Printf("could not update session: %v", err)
+		}
+	}
 
-	if err != nil {
-		log.Fatalf("could not login: %v", err)
+	return nil
+}
+
+// freshLogin signs in with the credentials of resolveCredentials, or waits
+// for a manual login.
+func (i *importProcess) freshLogin(ctx context.Context) error {
+	credentials := edubase.Credentials{}
+
+	// if email or password is empty, get credentials from the other sources
+	if manualLogin {
+		fmt.Fprintln(os.Stderr, "Manual login selected. Please complete the login in the opened browser window...")
+		fmt.Fprintln(os.Stderr, "For closing the application, close the browser window and press Ctrl+C in this terminal...")
+	} else {
+		c, err := resolveCredentials(os.Stdin, os.Stderr)
+		if err != nil {
+			return fmt.Errorf("could not get credentials: %w", err)
+		}
+
+		credentials = c
 	}
+
+	// login
+	if err := i.login(ctx, credentials); err != nil {
+		return fmt.Errorf("could not login: %w", err)
+	}
+
+	return nil
 }
 
-func (i *importProcess) getBooks() ([]edubase.Book, error) {
-	err := spinner.New().Title("fetching books...").
-		Action(func() {
-			_, err := i.libraryProvider.GetBooks()
-			if err != nil {
-				log.Fatalf("could not get books: %v", err)
-			}
-		}).
-		Run()
+func (i *importProcess) login(ctx context.Context, credentials edubase.Credentials) error {
+	loginSpinner := "logging in..."
+	if (credentials.Email == "" || credentials.Password == "") && !manualLogin {
+		loginSpinner = "login manually in open browser..."
+	}
+
+	return runWithSpinner(ctx, loginSpinner, func(ctx context.Context) error {
+		return i.loginProvider.LoginContext(ctx, credentials, manualLogin)
+	})
+}
+
+func (i *importProcess) getBooks(ctx context.Context) ([]edubase.Book, error) {
+	err := runWithSpinner(ctx, "fetching books...", func(ctx context.Context) error {
+		_, err := i.libraryProvider.GetBooksContext(ctx)
+		return err
+	})
 
 	return i.libraryProvider.Books, err
 }
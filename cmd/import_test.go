@@ -1,17 +1,24 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase/edubasetest"
+	pdfcpu "github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"github.com/playwright-community/playwright-go"
 )
 
 // newTestImportProcess creates a new import process with headless mode set based on CI environment
-func newTestImportProcess() (*importProcess, error) {
+func newTestImportProcess(opts ...edubase.Option) (*importProcess, error) {
 	pw, err := playwright.Run()
 	if err != nil {
 		return nil, fmt.Errorf("failed to run playwright: %w", err)
@@ -49,20 +56,27 @@ func newTestImportProcess() (*importProcess, error) {
 		return nil, fmt.Errorf("failed to launch browser: %w", err)
 	}
 
-	page, err := browser.NewPage(playwright.BrowserNewPageOptions{
+	browserContext, err := browser.NewContext(playwright.BrowserNewContextOptions{
 		Viewport: &playwright.Size{
 			Width:  *playwright.Int(width),
 			Height: *playwright.Int(height),
 		},
 	})
+	if err != nil {
+		browser.Close()
+		pw.Stop()
+		return nil, fmt.Errorf("failed to create browser context: %w", err)
+	}
+
+	page, err := browserContext.NewPage()
 	if err != nil {
 		browser.Close()
 		pw.Stop()
 		return nil, fmt.Errorf("failed to create page: %w", err)
 	}
 
-	loginProvider := edubase.NewLoginProvider(page)
-	libraryProvider := edubase.NewLibraryProvider(page)
+	loginProvider := edubase.NewLoginProvider(page, opts...)
+	libraryProvider := edubase.NewLibraryProvider(page, opts...)
 
 	return &importProcess{
 		page:            page,
@@ -132,29 +146,245 @@ func TestImport(t *testing.T) {
 	}
 }
 
-func TestSanitizeFilename(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{"normal_filename", "normal_filename"},
-		{"file/with/slash", "file_with_slash"},
-		{"file\\with\\backslash", "file_with_backslash"},
-		{"file:with:colon", "file_with_colon"},
-		{"file*with*asterisk", "file_with_asterisk"},
-		{"file?with?question", "file_with_question"},
-		{"file\"with\"quote", "file_with_quote"},
-		{"file<with<less", "file_with_less"},
-		{"file>with>greater", "file_with_greater"},
-		{"file|with|pipe", "file_with_pipe"},
-		{"all/\\:*?\"<>|chars", "all_________chars"},
-		{"", ""},
-	}
-
-	for _, tt := range tests {
-		result := sanitizeFilename(tt.input)
-		if result != tt.expected {
-			t.Errorf("sanitizeFilename(%q) = %q; want %q", tt.input, result, tt.expected)
+// setOfflineImportFlags points the import at server, writes its files to
+// temporary directories and restores the flags after the test.
+func setOfflineImportFlags(t *testing.T, server *edubasetest.Server) {
+	t.Helper()
+
+	oldBaseURL, oldTemp, oldOutput, oldDelay := baseURL, screenshotDir, outputPath, pageDelay
+	oldMode, oldText, oldWorkers, oldOverwrite := captureMode, textLayer, workers, imgOverwrite
+	oldPages, oldStart, oldMax, oldTimeout := pagesExpr, startPage, maxPages, timeout
+	t.Cleanup(func() {
+		baseURL, screenshotDir, outputPath, pageDelay = oldBaseURL, oldTemp, oldOutput, oldDelay
+		captureMode, textLayer, workers, imgOverwrite = oldMode, oldText, oldWorkers, oldOverwrite
+		pagesExpr, startPage, maxPages, timeout = oldPages, oldStart, oldMax, oldTimeout
+	})
+
+	baseURL = server.URL
+	screenshotDir = t.TempDir()
+	outputPath = t.TempDir()
+	pageDelay = 100 * time.Millisecond
+	captureMode = captureModeScreenshot
+	textLayer = true
+	workers = 1
+	imgOverwrite = false
+	pagesExpr, startPage, maxPages = "", 1, -1
+	timeout = time.Minute
+}
+
+// newOfflineImportProcess returns an import process that is logged in to
+// server, and the books of its library. The test is skipped if playwright
+// cannot be started.
+func newOfflineImportProcess(t *testing.T, server *edubasetest.Server) (*importProcess, map[int]edubase.Book) {
+	t.Helper()
+
+	importProcess, err := newTestImportProcess(providerOptions()...)
+	if err != nil {
+		t.Skipf("Skipping offline test: %v", err)
+	}
+	t.Cleanup(func() { importProcess.close() })
+	importProcess.outputNames = newOutputNames()
+
+	credentials := edubase.Credentials{
+		Email:    edubasetest.Email,
+		Password: edubasetest.Password,
+	}
+	if err := importProcess.loginProvider.Login(credentials, false); err != nil {
+		t.Fatalf("could not login: %v", err)
+	}
+
+	books, err := importProcess.libraryProvider.GetBooks()
+	if err != nil {
+		t.Fatalf("could not get books: %v", err)
+	}
+	byId := map[int]edubase.Book{}
+	for _, book := range books {
+		byId[book.Id] = book
+	}
+
+	return importProcess, byId
+}
+
+// readImportedPDF reads and validates the PDF at pdfPath and checks its page
+// count.
+func readImportedPDF(t *testing.T, pdfPath string, pageCount int) *model.Context {
+	t.Helper()
+
+	ctx, err := pdfcpu.ReadContextFile(pdfPath)
+	if err != nil {
+		t.Fatalf("could not read pdf: %v", err)
+	}
+	if err := pdfcpu.ValidateContext(ctx); err != nil {
+		t.Fatalf("invalid pdf: %v", err)
+	}
+	if ctx.PageCount != pageCount {
+		t.Errorf("got %d pages in pdf, want %d", ctx.PageCount, pageCount)
+	}
+	return ctx
+}
+
+func TestImportOffline(t *testing.T) {
+	server := edubasetest.NewServer()
+	defer server.Close()
+
+	setOfflineImportFlags(t, server)
+	importProcess, books := newOfflineImportProcess(t, server)
+
+	// pages 1 to 3 and 8 keep the first chapter and two of its sections
+	pagesExpr = "1-3,8"
+	book := books[58216]
+	pdfPath, err := importProcess.importBook(context.Background(), book)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if want := filepath.Join(outputPath, "Mathematik 1.pdf"); pdfPath != want {
+		t.Errorf("got pdf %s, want %s", pdfPath, want)
+	}
+
+	readImportedPDF(t, pdfPath, 4)
+	if id, ok := pdfBookId(pdfPath); !ok || id != book.Id {
+		t.Errorf("got book id %d (%v) in metadata, want %d", id, ok, book.Id)
+	}
+
+	f, err := os.Open(pdfPath)
+	if err != nil {
+		t.Fatalf("could not open pdf: %v", err)
+	}
+	defer f.Close()
+	bookmarks, err := pdfcpu.Bookmarks(f, nil)
+	if err != nil {
+		t.Fatalf("could not read bookmarks: %v", err)
+	}
+	if got, want := pdfcpuOutlineString(bookmarks), "[1 Zahlen:1[1.1 Natürliche Zahlen:2][1.2 Brüche:4]]"; got != want {
+		t.Errorf("got outline %s, want %s", got, want)
+	}
+
+	pageText, err := loadPageText(filepath.Join(screenshotDir, fmt.Sprintf("%d_8.json", book.Id)))
+	if err != nil {
+		t.Fatalf("could not load page text: %v", err)
+	}
+	if !pageTextContains(pageText, "Page 8 of 38") {
+		t.Errorf("text of page 8 is %+v, want it to contain %q", pageText.Runs, "Page 8 of 38")
+	}
+
+	// a second import reuses the captured pages of the manifest
+	screenshot := filepath.Join(screenshotDir, fmt.Sprintf("%d_1.jpeg", book.Id))
+	before, err := os.Stat(screenshot)
+	if err != nil {
+		t.Fatalf("no screenshot of page 1: %v", err)
+	}
+	if _, err := importProcess.importBook(context.Background(), book); err != nil {
+		t.Fatalf("second import failed: %v", err)
+	}
+	after, err := os.Stat(screenshot)
+	if err != nil {
+		t.Fatalf("no screenshot of page 1: %v", err)
+	}
+	if !after.ModTime().Equal(before.ModTime()) {
+		t.Errorf("page 1 was captured again instead of being reused")
+	}
+}
+
+func TestImportOfflinePageLabels(t *testing.T) {
+	server := edubasetest.NewServer()
+	defer server.Close()
+
+	setOfflineImportFlags(t, server)
+	importProcess, books := newOfflineImportProcess(t, server)
+
+	outputPath = filepath.Join(outputPath, "{{.Id}}", "{{.Title}}.pdf")
+	pagesExpr = "1-4"
+	workers = 2
+	book := books[61532]
+	pdfPath, err := importProcess.importBook(context.Background(), book)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if filepath.Base(filepath.Dir(pdfPath)) != "61532" || filepath.Base(pdfPath) != "Deutsch_ Grammatik _ Übungen.pdf" {
+		t.Errorf("got pdf %s, want 61532/Deutsch_ Grammatik _ Übungen.pdf", pdfPath)
+	}
+
+	ctx := readImportedPDF(t, pdfPath, 4)
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("could not read catalog: %v", err)
+	}
+	if catalog["PageLabels"] == nil {
+		t.Errorf("pdf has no page labels")
+	}
+}
+
+func TestImportOfflineVector(t *testing.T) {
+	server := edubasetest.NewServer()
+	defer server.Close()
+
+	setOfflineImportFlags(t, server)
+	importProcess, books := newOfflineImportProcess(t, server)
+
+	renderer, err := newSVGRenderer(importProcess.pw)
+	if err != nil {
+		t.Fatalf("could not start renderer: %v", err)
+	}
+	importProcess.renderer = renderer
+
+	captureMode = captureModeSVG
+	pagesExpr = "1-3"
+	book := books[58216]
+	pdfPath, err := importProcess.importBook(context.Background(), book)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	ctx := readImportedPDF(t, pdfPath, 3)
+	if ctx.Title != book.Title {
+		t.Errorf("got title %q, want %q", ctx.Title, book.Title)
+	}
+}
+
+func TestImportOfflineTimeout(t *testing.T) {
+	server := edubasetest.NewServer()
+	defer server.Close()
+
+	// the pages of the first book never load in time
+	server.Books[0].PageDelay = time.Minute
+
+	setOfflineImportFlags(t, server)
+	importProcess, books := newOfflineImportProcess(t, server)
+
+	pagesExpr = "1-2"
+	timeout = 5 * time.Second
+	results := importProcess.importBooks(context.Background(), []edubase.Book{books[58216], books[61532]})
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	if results[0].err == nil {
+		t.Errorf("book 58216 was imported, want it to run out of time")
+	}
+	if results[1].err != nil {
+		t.Fatalf("book 61532 failed after book 58216 ran out of time: %v", results[1].err)
+	}
+
+	readImportedPDF(t, results[1].pdfPath, 2)
+	if id, ok := pdfBookId(results[1].pdfPath); !ok || id != 61532 {
+		t.Errorf("got book id %d (%v) in metadata, want %d", id, ok, 61532)
+	}
+
+	pageText, err := loadPageText(filepath.Join(screenshotDir, "61532_1.json"))
+	if err != nil {
+		t.Fatalf("could not load page text: %v", err)
+	}
+	if !pageTextContains(pageText, "Page 1 of 12") {
+		t.Errorf("text of page 1 is %+v, want it to contain %q", pageText.Runs, "Page 1 of 12")
+	}
+}
+
+// pageTextContains reports whether a text run of pageText contains text.
+func pageTextContains(pageText edubase.PageText, text string) bool {
+	for _, run := range pageText.Runs {
+		if strings.Contains(run.Text, text) {
+			return true
 		}
 	}
+	return false
 }
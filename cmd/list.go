@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"github.com/playwright-community/playwright-go"
+	"github.com/spf13/cobra"
+)
+
+const (
+	listFormatTable = "table"
+	listFormatJSON  = "json"
+	listFormatCSV   = "csv"
+)
+
+var listFormat string = listFormatTable
+
+func init() {
+	listCmd.Flags().StringVarP(&email, "email", "e", "", "Edubase email for login. Defaults to $EDUBASE_EMAIL.")
+	listCmd.Flags().StringVarP(&password, "password", "p", "", "Edubase password for login. It is visible to other users in the process list, prefer --password-stdin, --password-file or $EDUBASE_PASSWORD.")
+	listCmd.Flags().BoolVar(&passwordStdin, "password-stdin", passwordStdin, "Read the password from the first line of stdin.")
+	listCmd.Flags().StringVar(&passwordFile, "password-file", passwordFile, "Read the password from the first line of the file.")
+	listCmd.Flags().StringVar(&profile, "profile", profile, "Read the credentials from this profile of the credential store, see the credentials command.")
+	listCmd.Flags().BoolVarP(&debug, "debug", "d", false, "Debug mode. Show browser window.")
+	listCmd.Flags().BoolVarP(&manualLogin, "manual", "M", false, "Type your credentials manually. This is useful if you use Microsoft login.")
+	listCmd.Flags().StringVar(&loginMethod, "login-method", loginMethod, "How to log in with the credentials: \"edubase\" or \"microsoft\" for Microsoft accounts of schools.")
+	listCmd.Flags().DurationVarP(&timeout, "timeout", "T", timeout, "Maximum time the app can take to fetch the library.")
+	listCmd.Flags().StringVarP(&listFormat, "format", "f", listFormat, "Output format: \"table\", \"json\" or \"csv\".")
+
+	listCmd.MarkFlagsMutuallyExclusive("manual", "login-method")
+	listCmd.MarkFlagsMutuallyExclusive("profile", "email")
+	listCmd.MarkFlagsMutuallyExclusive("profile", "password", "password-stdin", "password-file")
+
+	rootCmd.AddCommand(listCmd)
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the books of your library",
+	Long: `Description:
+  The list command will sign in to Edubase and print the books of your library with their ID,
+  title and version. Use the ID with "import --book-id" to import a book without prompts.
+
+Example:
+  edubase-to-pdf list -e your_email@example.com -p your_password --format csv
+
+  This example prints the library as CSV, e.g. to process it in a script.
+
+Contact:
+  For any issues or questions, please open an issue on the GitHub repository:
+  https://github.com/michaelbeutler/edubase-to-pdf/issues`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if listFormat != listFormatTable && listFormat != listFormatJSON && listFormat != listFormatCSV {
+			log.Fatalf("invalid format %q: must be %q, %q or %q", listFormat, listFormatTable, listFormatJSON, listFormatCSV)
+		}
+
+		if err := checkLoginMethod(); err != nil {
+			log.Fatalf("%v", err)
+		}
+
+		err := playwright.Install()
+		if err != nil {
+			log.Fatalf("could not install Playwright: %v", err)
+		}
+
+		ctx, cancel := newCommandContext(cmd.Context())
+		defer cancel()
+		ctx, cancelTimeout := context.WithTimeout(ctx, timeout)
+		defer cancelTimeout()
+
+		if err := runList(ctx, os.Stdout, useSession(cmd.Flags())); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// runList signs in, or uses the session file with loadSession, and prints
+// the library to w.
+func runList(ctx context.Context, w io.Writer, loadSession bool) error {
+	importProcess, err := newImportProcess(loadSession)
+	if err != nil {
+		return err
+	}
+	defer importProcess.close()
+
+	defer importProcess.closeWhenDone(ctx)()
+
+	if err := importProcess.authenticate(ctx); err != nil {
+		return err
+	}
+
+	books, err := importProcess.getBooks(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get books: %w", err)
+	}
+
+	if err := printBooks(w, books, listFormat); err != nil {
+		return fmt.Errorf("could not print books: %w", err)
+	}
+
+	return importProcess.close()
+}
+
+// printBooks writes books to w in the given format.
+func printBooks(w io.Writer, books []edubase.Book, format string) error {
+	switch format {
+	case listFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(books)
+
+	case listFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"id", "title", "version"}); err != nil {
+			return err
+		}
+		for _, book := range books {
+			if err := writer.Write([]string{strconv.Itoa(book.Id), book.Title, book.Version}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+
+	case listFormatTable:
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "ID\tTITLE\tVERSION")
+		for _, book := range books {
+			fmt.Fprintf(writer, "%d\t%s\t%s\n", book.Id, book.Title, book.Version)
+		}
+		return writer.Flush()
+
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
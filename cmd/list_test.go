@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+)
+
+var listTestBooks = []edubase.Book{
+	{Id: 58216, Title: "Mathematik 1", Version: "3. Auflage 2023"},
+	{Id: 61532, Title: "Deutsch: Grammatik, Übungen"},
+}
+
+func TestPrintBooksTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printBooks(&buf, listTestBooks, listFormatTable); err != nil {
+		t.Fatalf("print books failed: %v", err)
+	}
+
+	expected := "ID     TITLE                        VERSION\n" +
+		"58216  Mathematik 1                 3. Auflage 2023\n" +
+		"61532  Deutsch: Grammatik, Übungen  \n"
+	if buf.String() != expected {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), expected)
+	}
+}
+
+func TestPrintBooksJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printBooks(&buf, listTestBooks, listFormatJSON); err != nil {
+		t.Fatalf("print books failed: %v", err)
+	}
+
+	var books []edubase.Book
+	if err := json.Unmarshal(buf.Bytes(), &books); err != nil {
+		t.Fatalf("output is not valid json: %v\n%s", err, buf.String())
+	}
+	if !reflect.DeepEqual(books, listTestBooks) {
+		t.Errorf("got %+v, want %+v", books, listTestBooks)
+	}
+	if !strings.Contains(buf.String(), `"id": 58216`) {
+		t.Errorf("output does not use lower case keys:\n%s", buf.String())
+	}
+}
+
+func TestPrintBooksCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printBooks(&buf, listTestBooks, listFormatCSV); err != nil {
+		t.Fatalf("print books failed: %v", err)
+	}
+
+	expected := "id,title,version\n" +
+		"58216,Mathematik 1,3. Auflage 2023\n" +
+		"61532,\"Deutsch: Grammatik, Übungen\",\n"
+	if buf.String() != expected {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), expected)
+	}
+}
+
+func TestPrintBooksUnknownFormat(t *testing.T) {
+	if err := printBooks(&bytes.Buffer{}, listTestBooks, "xml"); err == nil {
+		t.Errorf("printing books as xml should have failed")
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/playwright-community/playwright-go"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	loginCmd.Flags().StringVarP(&email, "email", "e", "", "Edubase email for login. Defaults to $EDUBASE_EMAIL.")
+	loginCmd.Flags().StringVarP(&password, "password", "p", "", "Edubase password for login. It is visible to other users in the process list, prefer --password-stdin, --password-file or $EDUBASE_PASSWORD.")
+	loginCmd.Flags().BoolVar(&passwordStdin, "password-stdin", passwordStdin, "Read the password from the first line of stdin.")
+	loginCmd.Flags().StringVar(&passwordFile, "password-file", passwordFile, "Read the password from the first line of the file.")
+	loginCmd.Flags().StringVar(&profile, "profile", profile, "Read the credentials from this profile of the credential store, see the credentials command.")
+	loginCmd.Flags().BoolVarP(&debug, "debug", "d", false, "Debug mode. Show browser window.")
+	loginCmd.Flags().BoolVarP(&manualLogin, "manual", "M", false, "Type your credentials manually. This is useful if you use Microsoft login.")
+	loginCmd.Flags().StringVar(&loginMethod, "login-method", loginMethod, "How to log in with the credentials: \"edubase\" or \"microsoft\" for Microsoft accounts of schools.")
+	loginCmd.Flags().DurationVarP(&timeout, "timeout", "T", timeout, "Maximum time the app can take to log in.")
+
+	loginCmd.MarkFlagsMutuallyExclusive("manual", "login-method")
+	loginCmd.MarkFlagsMutuallyExclusive("profile", "email")
+	loginCmd.MarkFlagsMutuallyExclusive("profile", "password", "password-stdin", "password-file")
+
+	rootCmd.AddCommand(loginCmd)
+}
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in once and save the session for later runs",
+	Long: `Description:
+  The login command will sign in to Edubase and save the session to the session file (see --session).
+  As long as the session is valid, import and list use it instead of logging in again. Once it has
+  expired, they log in as usual and update the session file. Credentials passed as flags (--email,
+  --password, --password-stdin, --password-file or --profile) always log in instead, credentials of
+  the environment or the config file are only used once the session is missing or has expired.
+
+Example:
+  edubase-to-pdf login --manual
+
+  This example opens a browser window to log in, e.g. with Microsoft, and saves the session.
+
+Contact:
+  For any issues or questions, please open an issue on the GitHub repository:
+  https://github.com/michaelbeutler/edubase-to-pdf/issues`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkLoginMethod(); err != nil {
+			log.Fatalf("%v", err)
+		}
+
+		err := playwright.Install()
+		if err != nil {
+			log.Fatalf("could not install Playwright: %v", err)
+		}
+
+		ctx, cancel := newCommandContext(cmd.Context())
+		defer cancel()
+		ctx, cancelTimeout := context.WithTimeout(ctx, timeout)
+		defer cancelTimeout()
+
+		if err := runLogin(ctx); err != nil {
+			exitWithError(err)
+		}
+
+		fmt.Printf("Session saved to %s\n", sessionPath)
+	},
+}
+
+// runLogin signs in and saves the session to the session file.
+func runLogin(ctx context.Context) error {
+	if sessionPath == "" {
+		return errors.New("no session file: set --session to the file the session should be saved to")
+	}
+
+	// always log in, the existing session may belong to another account
+	importProcess, err := newImportProcess(false)
+	if err != nil {
+		return err
+	}
+	defer importProcess.close()
+
+	defer importProcess.closeWhenDone(ctx)()
+
+	if err := importProcess.freshLogin(ctx); err != nil {
+		return err
+	}
+
+	if err := importProcess.loginProvider.SaveSessionContext(ctx, sessionPath); err != nil {
+		return fmt.Errorf("could not save session: %w", err)
+	}
+
+	return importProcess.close()
+}
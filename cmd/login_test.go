@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestSessionExists(t *testing.T) {
+	defer func(path string) { sessionPath = path }(sessionPath)
+
+	sessionPath = ""
+	if sessionExists() {
+		t.Error("expected no session without a session file")
+	}
+
+	sessionPath = filepath.Join(t.TempDir(), "session.json")
+	if sessionExists() {
+		t.Error("expected no session before the file is written")
+	}
+
+	if err := os.WriteFile(sessionPath, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if !sessionExists() {
+		t.Error("expected session after the file is written")
+	}
+}
+
+func TestRunLoginWithoutSessionPath(t *testing.T) {
+	defer func(path string) { sessionPath = path }(sessionPath)
+
+	sessionPath = ""
+	if err := runLogin(context.Background()); err == nil {
+		t.Error("expected error without a session file")
+	}
+}
+
+// newSessionTestCommand returns a command with the credential flags bound
+// to the package variables.
+func newSessionTestCommand(t *testing.T) *cobra.Command {
+	t.Helper()
+
+	oldEmail, oldPassword, oldProfile := email, password, profile
+	oldStdin, oldFile := passwordStdin, passwordFile
+	t.Cleanup(func() {
+		email, password, profile = oldEmail, oldPassword, oldProfile
+		passwordStdin, passwordFile = oldStdin, oldFile
+	})
+
+	c := &cobra.Command{Use: "test"}
+	c.Flags().StringVarP(&email, "email", "e", "", "")
+	c.Flags().StringVarP(&password, "password", "p", "", "")
+	c.Flags().BoolVar(&passwordStdin, "password-stdin", false, "")
+	c.Flags().StringVar(&passwordFile, "password-file", "", "")
+	c.Flags().StringVar(&profile, "profile", "", "")
+	return c
+}
+
+func TestUseSession(t *testing.T) {
+	defer func(path string) { sessionPath = path }(sessionPath)
+
+	sessionPath = filepath.Join(t.TempDir(), "session.json")
+	if err := os.WriteFile(sessionPath, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{name: "no credentials", want: true},
+		{name: "email", args: []string{"--email", "a@b.c"}, want: false},
+		{name: "password", args: []string{"-p", "secret"}, want: false},
+		{name: "password stdin", args: []string{"--password-stdin"}, want: false},
+		{name: "password file", args: []string{"--password-file", "pw.txt"}, want: false},
+		{name: "profile", args: []string{"--profile", "school"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newSessionTestCommand(t)
+			if err := c.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := useSession(c.Flags()); got != tt.want {
+				t.Errorf("useSession with %v = %v; want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUseSessionEnvAndConfig(t *testing.T) {
+	defer func(path string) { sessionPath = path }(sessionPath)
+
+	sessionPath = filepath.Join(t.TempDir(), "session.json")
+	if err := os.WriteFile(sessionPath, []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	setConfig(t, "defaults:\n  profile: school\n", "")
+	t.Setenv("EDUBASE_CONFIG_PROFILE", "")
+	t.Setenv(emailEnv, "a@b.c")
+	t.Setenv(passwordEnv, "secret")
+
+	c := newSessionTestCommand(t)
+	if _, err := applyConfig(c); err != nil {
+		t.Fatalf("apply config failed: %v", err)
+	}
+	if profile != "school" {
+		t.Fatalf("got profile %q from the config, want %q", profile, "school")
+	}
+
+	if !useSession(c.Flags()) {
+		t.Error("expected the session to be used with credentials of the environment and the config file")
+	}
+
+	sessionPath = ""
+	if useSession(c.Flags()) {
+		t.Error("expected no session without a session file")
+	}
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/atomicfile"
+)
+
+// captureSettings are the settings that change what a captured page looks
+// like. Pages captured with other settings are captured again.
+type captureSettings struct {
+	Mode   string `json:"mode"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// manifest records the pages of a book captured in the temporary directory,
+// so an interrupted import can resume where it stopped. It is safe for
+// concurrent use.
+type manifest struct {
+	BookId int `json:"bookId"`
+	// Pages maps page numbers to the files captured for them.
+	Pages map[int]manifestPage `json:"pages"`
+
+	mu sync.Mutex
+}
+
+type manifestPage struct {
+	Files    []manifestFile  `json:"files"`
+	Settings captureSettings `json:"settings"`
+	// Label is the page number the reader showed, empty if it was unknown.
+	Label      string    `json:"label,omitempty"`
+	CapturedAt time.Time `json:"capturedAt"`
+}
+
+type manifestFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// manifestPath returns the path of the manifest of a book in dir.
+func manifestPath(dir string, bookId int) string {
+	return filepath.Join(dir, fmt.Sprintf("%d_manifest.json", bookId))
+}
+
+func newManifest(bookId int) *manifest {
+	return &manifest{
+		BookId: bookId,
+		Pages:  map[int]manifestPage{},
+	}
+}
+
+// loadManifest reads the manifest at path. A missing manifest, or one of
+// another book, results in an empty manifest.
+func loadManifest(path string, bookId int) (*manifest, error) {
+	m := newManifest(bookId)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest: %w", err)
+	}
+
+	loaded := &manifest{}
+	if err := json.Unmarshal(data, loaded); err != nil {
+		return nil, fmt.Errorf("could not parse manifest %s: %w", path, err)
+	}
+
+	if loaded.BookId != bookId || loaded.Pages == nil {
+		return m, nil
+	}
+
+	return loaded, nil
+}
+
+// save writes the manifest to path. An interruption cannot leave a
+// truncated manifest behind.
+func (m *manifest) save(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.WriteFile(path, data, 0644)
+}
+
+// needsCapture reports whether page has to be captured again: it was never
+// recorded, was captured with other settings or into other files, or one
+// of its files is missing or does not match its recorded hash.
+func (m *manifest) needsCapture(page int, files []string, settings captureSettings) bool {
+	m.mu.Lock()
+	entry, ok := m.Pages[page]
+	m.mu.Unlock()
+
+	if !ok || entry.Settings != settings || len(entry.Files) != len(files) {
+		return true
+	}
+
+	for i, file := range entry.Files {
+		if file.Path != files[i] {
+			return true
+		}
+
+		hash, err := hashFile(file.Path)
+		if err != nil || hash != file.SHA256 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// record adds the files captured for page and its label to the manifest.
+func (m *manifest) record(page int, files []string, settings captureSettings, label string) error {
+	entry := manifestPage{
+		Files:      make([]manifestFile, 0, len(files)),
+		Settings:   settings,
+		Label:      label,
+		CapturedAt: time.Now().UTC(),
+	}
+
+	for _, file := range files {
+		hash, err := hashFile(file)
+		if err != nil {
+			return fmt.Errorf("could not hash %s: %w", file, err)
+		}
+		entry.Files = append(entry.Files, manifestFile{Path: file, SHA256: hash})
+	}
+
+	m.mu.Lock()
+	m.Pages[page] = entry
+	m.mu.Unlock()
+
+	return nil
+}
+
+// label returns the recorded label of page, or "" if there is none.
+func (m *manifest) label(page int) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Pages[page].Label
+}
+
+// hashFile returns the hex encoded SHA-256 hash of the file.
+func hashFile(filename string) (string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	path := manifestPath(dir, 58216)
+
+	settings := captureSettings{Mode: captureModeScreenshot, Width: 2560, Height: 1440}
+	image := filepath.Join(dir, "58216_1.jpeg")
+	text := filepath.Join(dir, "58216_1.json")
+	files := []string{image, text}
+
+	m, err := loadManifest(path, 58216)
+	if err != nil {
+		t.Fatalf("could not load missing manifest: %v", err)
+	}
+	if !m.needsCapture(1, files, settings) {
+		t.Errorf("page missing from the manifest does not need capture")
+	}
+
+	writeTestJPEG(t, image, 10, 10)
+	if err := os.WriteFile(text, []byte(`{"width":10,"height":10,"runs":[]}`), 0644); err != nil {
+		t.Fatalf("could not write text: %v", err)
+	}
+
+	if err := m.record(1, files, settings, "iv"); err != nil {
+		t.Fatalf("could not record page: %v", err)
+	}
+	if err := m.save(path); err != nil {
+		t.Fatalf("could not save manifest: %v", err)
+	}
+
+	m, err = loadManifest(path, 58216)
+	if err != nil {
+		t.Fatalf("could not load manifest: %v", err)
+	}
+
+	entry, ok := m.Pages[1]
+	if !ok {
+		t.Fatalf("page 1 is missing from the saved manifest")
+	}
+	if entry.CapturedAt.IsZero() {
+		t.Errorf("page 1 has no capture time")
+	}
+	if m.label(1) != "iv" {
+		t.Errorf("got label %q for page 1, want %q", m.label(1), "iv")
+	}
+	if m.label(2) != "" {
+		t.Errorf("got label %q for unrecorded page 2, want none", m.label(2))
+	}
+
+	if m.needsCapture(1, files, settings) {
+		t.Errorf("recorded page needs capture")
+	}
+	if !m.needsCapture(2, files, settings) {
+		t.Errorf("unrecorded page does not need capture")
+	}
+	if !m.needsCapture(1, files, captureSettings{Mode: captureModeScreenshot, Width: 1920, Height: 1080}) {
+		t.Errorf("page captured at another size does not need capture")
+	}
+	if !m.needsCapture(1, files[:1], settings) {
+		t.Errorf("page captured with text layer does not need capture without it")
+	}
+
+	// a truncated screenshot must be captured again
+	if err := os.Truncate(image, 10); err != nil {
+		t.Fatalf("could not truncate image: %v", err)
+	}
+	if !m.needsCapture(1, files, settings) {
+		t.Errorf("truncated page does not need capture")
+	}
+
+	if err := os.Remove(image); err != nil {
+		t.Fatalf("could not remove image: %v", err)
+	}
+	if !m.needsCapture(1, files, settings) {
+		t.Errorf("deleted page does not need capture")
+	}
+}
+
+func TestLoadManifestOtherBook(t *testing.T) {
+	dir := t.TempDir()
+	path := manifestPath(dir, 58216)
+
+	m := newManifest(61532)
+	m.Pages[1] = manifestPage{}
+	if err := m.save(path); err != nil {
+		t.Fatalf("could not save manifest: %v", err)
+	}
+
+	loaded, err := loadManifest(path, 58216)
+	if err != nil {
+		t.Fatalf("could not load manifest: %v", err)
+	}
+	if loaded.BookId != 58216 || len(loaded.Pages) != 0 {
+		t.Errorf("got manifest of book %d with %d pages, want empty manifest of book 58216", loaded.BookId, len(loaded.Pages))
+	}
+}
+
+func TestLoadManifestCorrupt(t *testing.T) {
+	path := manifestPath(t.TempDir(), 58216)
+	if err := os.WriteFile(path, []byte(`{"bookId":58216,"pages":`), 0644); err != nil {
+		t.Fatalf("could not write manifest: %v", err)
+	}
+
+	if _, err := loadManifest(path, 58216); err == nil {
+		t.Errorf("loading a corrupt manifest should have failed")
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/atomicfile"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/pdf"
+	pdfcpu "github.com/pdfcpu/pdfcpu/pkg/api"
+	pdfcore "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// edubaseKeyword is the keyword of the PDF metadata that is followed by the
+// Edubase id of the book.
+const edubaseKeyword = "Edubase "
+
+// bookMetadata returns the metadata of the PDF of book. The subject names
+// the edition and publisher, the keywords hold the ISBN and the Edubase id,
+// which help to find the book again.
+func bookMetadata(book edubase.Book) pdf.Metadata {
+	keywords := []string{}
+	if book.ISBN != "" {
+		keywords = append(keywords, "ISBN "+book.ISBN)
+	}
+	keywords = append(keywords, edubaseKeyword+strconv.Itoa(book.Id))
+
+	return pdf.Metadata{
+		Title:     book.Title,
+		Authors:   book.Authors,
+		Subject:   joinNonEmpty(", ", book.Version, book.Publisher),
+		Keywords:  keywords,
+		Publisher: book.Publisher,
+		ISBN:      book.ISBN,
+		Edition:   book.Version,
+		Creator:   "edubase-to-pdf",
+	}
+}
+
+// pdfBookId returns the Edubase id of the book in the keywords of the PDF at
+// pdfPath. It reports false if the file is no PDF or has no such keyword,
+// e.g. because it was written by an older version.
+func pdfBookId(pdfPath string) (int, bool) {
+	ctx, err := pdfcpu.ReadContextFile(pdfPath)
+	if err != nil || ctx.Info == nil {
+		return 0, false
+	}
+
+	info, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil || info == nil {
+		return 0, false
+	}
+	keywords, err := ctx.DereferenceStringOrHexLiteral(info["Keywords"], model.V10, nil)
+	if err != nil {
+		return 0, false
+	}
+
+	for _, keyword := range strings.Split(keywords, ",") {
+		if id, found := strings.CutPrefix(strings.TrimSpace(keyword), edubaseKeyword); found {
+			if n, err := strconv.Atoi(id); err == nil {
+				return n, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func joinNonEmpty(sep string, values ...string) string {
+	nonEmpty := []string{}
+	for _, value := range values {
+		if value != "" {
+			nonEmpty = append(nonEmpty, value)
+		}
+	}
+	return strings.Join(nonEmpty, sep)
+}
+
+// setMetadata writes metadata into the information dictionary and as XMP
+// metadata stream of a PDF, e.g. one merged by pdfcpu.
+func setMetadata(ctx *model.Context, metadata pdf.Metadata) error {
+	if err := pdfcore.PropertiesAdd(ctx, metadata.Info()); err != nil {
+		return fmt.Errorf("could not add document info: %w", err)
+	}
+
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return fmt.Errorf("could not read catalog: %w", err)
+	}
+
+	// without filter the stream is written uncompressed, as XMP requires
+	stream := types.StreamDict{Dict: types.NewDict(), Content: metadata.XMP()}
+	stream.InsertName("Type", "Metadata")
+	stream.InsertName("Subtype", "XML")
+	if err := stream.Encode(); err != nil {
+		return fmt.Errorf("could not encode XMP metadata: %w", err)
+	}
+	ref, err := ctx.IndRefForNewObject(stream)
+	if err != nil {
+		return fmt.Errorf("could not add XMP metadata: %w", err)
+	}
+	catalog.Update("Metadata", *ref)
+
+	return nil
+}
+
+// updatePDF reads the PDF at pdfPath, lets update change it and writes it
+// back. A failure leaves the original untouched.
+func updatePDF(pdfPath string, update func(ctx *model.Context) error) error {
+	ctx, err := pdfcpu.ReadContextFile(pdfPath)
+	if err != nil {
+		return fmt.Errorf("could not read PDF: %w", err)
+	}
+
+	if err := update(ctx); err != nil {
+		return err
+	}
+
+	return atomicfile.Write(pdfPath, 0644, func(tmpPath string) error {
+		if err := pdfcpu.WriteContextFile(ctx, tmpPath); err != nil {
+			return fmt.Errorf("could not write PDF: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/pdf"
+	pdfcpu "github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+func TestBookMetadata(t *testing.T) {
+	book := edubase.Book{
+		Id:        58216,
+		Title:     "Mathematik 1",
+		Version:   "3. Auflage 2023",
+		Authors:   []string{"Anna Muster"},
+		Publisher: "Lehrmittelverlag Zürich",
+		ISBN:      "978-3-03713-123-4",
+	}
+
+	want := pdf.Metadata{
+		Title:     "Mathematik 1",
+		Authors:   []string{"Anna Muster"},
+		Subject:   "3. Auflage 2023, Lehrmittelverlag Zürich",
+		Keywords:  []string{"ISBN 978-3-03713-123-4", "Edubase 58216"},
+		Publisher: "Lehrmittelverlag Zürich",
+		ISBN:      "978-3-03713-123-4",
+		Edition:   "3. Auflage 2023",
+		Creator:   "edubase-to-pdf",
+	}
+	if got := bookMetadata(book); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// books without details still get a title and keywords
+	got := bookMetadata(edubase.Book{Id: 61532, Title: "Deutsch"})
+	if got.Subject != "" || !reflect.DeepEqual(got.Keywords, []string{"Edubase 61532"}) {
+		t.Errorf("got %+v, want no subject and only the Edubase id as keyword", got)
+	}
+}
+
+func TestSetMetadata(t *testing.T) {
+	dir := t.TempDir()
+
+	image := filepath.Join(dir, "1.jpeg")
+	writeTestJPEG(t, image, 119, 168)
+
+	pdfPath := filepath.Join(dir, "book.pdf")
+	if err := pdfcpu.ImportImagesFile([]string{image}, pdfPath, nil, model.NewDefaultConfiguration()); err != nil {
+		t.Fatalf("could not create pdf: %v", err)
+	}
+
+	metadata := bookMetadata(edubase.Book{
+		Id:        58216,
+		Title:     "Mathematik 1",
+		Authors:   []string{"Anna Muster", "Beat Beispiel"},
+		Publisher: "Lehrmittelverlag Zürich",
+		ISBN:      "978-3-03713-123-4",
+	})
+	err := updatePDF(pdfPath, func(ctx *model.Context) error {
+		return setMetadata(ctx, metadata)
+	})
+	if err != nil {
+		t.Fatalf("set metadata failed: %v", err)
+	}
+
+	ctx, err := pdfcpu.ReadContextFile(pdfPath)
+	if err != nil {
+		t.Fatalf("could not read pdf: %v", err)
+	}
+	if err := pdfcpu.ValidateContext(ctx); err != nil {
+		t.Fatalf("invalid pdf: %v", err)
+	}
+
+	if ctx.Title != "Mathematik 1" {
+		t.Errorf("got title %q, want %q", ctx.Title, "Mathematik 1")
+	}
+	if ctx.Author != "Anna Muster, Beat Beispiel" {
+		t.Errorf("got author %q, want %q", ctx.Author, "Anna Muster, Beat Beispiel")
+	}
+	if ctx.Properties["Publisher"] != "Lehrmittelverlag Zürich" {
+		t.Errorf("got publisher %q, want %q", ctx.Properties["Publisher"], "Lehrmittelverlag Zürich")
+	}
+
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("could not read catalog: %v", err)
+	}
+	if catalog.IndirectRefEntry("Metadata") == nil {
+		t.Errorf("catalog has no XMP metadata")
+	}
+}
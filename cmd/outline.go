@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/pdf"
+	pdfcore "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// bookmarksFromTOC converts the table of contents of a book into PDF
+// bookmarks for a PDF that holds the book pages bookPages in this order.
+// Chapters that start on a page missing from the PDF are dropped, their
+// sub-chapters inside of it move up a level.
+func bookmarksFromTOC(toc []edubase.TOCEntry, bookPages []int) []pdf.Bookmark {
+	pdfPages := make(map[int]int, len(bookPages))
+	for i, n := range bookPages {
+		pdfPages[n] = i + 1
+	}
+	return bookmarksFromEntries(toc, pdfPages, 1)
+}
+
+// bookmarksFromEntries converts a list of sibling chapters. PDF viewers
+// expect siblings in page order and sub-chapters not to start before their
+// parent, so pages going backwards are raised to minPage.
+func bookmarksFromEntries(entries []edubase.TOCEntry, pdfPages map[int]int, minPage int) []pdf.Bookmark {
+	bookmarks := []pdf.Bookmark{}
+
+	for _, entry := range entries {
+		page, ok := pdfPages[entry.Page]
+		if !ok || entry.Title == "" {
+			for _, bookmark := range bookmarksFromEntries(entry.Children, pdfPages, minPage) {
+				bookmarks = append(bookmarks, bookmark)
+				minPage = bookmark.Page
+			}
+			continue
+		}
+
+		page = max(page, minPage)
+		bookmarks = append(bookmarks, pdf.Bookmark{
+			Title: entry.Title,
+			Page:  page,
+			Kids:  bookmarksFromEntries(entry.Children, pdfPages, page),
+		})
+		minPage = page
+	}
+
+	return bookmarks
+}
+
+// setOutline sets bookmarks as outline of a PDF, replacing any existing one.
+// Nothing is changed if there are no bookmarks.
+func setOutline(ctx *model.Context, bookmarks []pdf.Bookmark) error {
+	if len(bookmarks) == 0 {
+		return nil
+	}
+
+	if err := pdfcore.AddBookmarks(ctx, pdfcpuBookmarks(bookmarks), true); err != nil {
+		return fmt.Errorf("could not write bookmarks: %w", err)
+	}
+
+	return nil
+}
+
+func pdfcpuBookmarks(bookmarks []pdf.Bookmark) []pdfcore.Bookmark {
+	converted := make([]pdfcore.Bookmark, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		converted = append(converted, pdfcore.Bookmark{
+			Title:    bookmark.Title,
+			PageFrom: bookmark.Page,
+			Kids:     pdfcpuBookmarks(bookmark.Kids),
+		})
+	}
+	return converted
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/pdf"
+	pdfcpu "github.com/pdfcpu/pdfcpu/pkg/api"
+	pdfcore "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+var testTOC = []edubase.TOCEntry{
+	{Title: "1 Zahlen", Page: 1, Children: []edubase.TOCEntry{
+		{Title: "1.1 Natürliche Zahlen", Page: 2},
+		{Title: "1.2 Brüche", Page: 8},
+	}},
+	{Title: "2 Geometrie", Page: 15},
+	{Title: "3 Algebra", Page: 27, Children: []edubase.TOCEntry{
+		{Title: "3.1 Terme", Page: 28},
+	}},
+}
+
+// outlineString flattens bookmarks for easy comparison.
+func outlineString(bookmarks []pdf.Bookmark) string {
+	s := ""
+	for _, bookmark := range bookmarks {
+		s += fmt.Sprintf("[%s:%d%s]", bookmark.Title, bookmark.Page, outlineString(bookmark.Kids))
+	}
+	return s
+}
+
+// pdfcpuOutlineString is like outlineString for bookmarks read by pdfcpu.
+func pdfcpuOutlineString(bookmarks []pdfcore.Bookmark) string {
+	s := ""
+	for _, bookmark := range bookmarks {
+		s += fmt.Sprintf("[%s:%d%s]", bookmark.Title, bookmark.PageFrom, pdfcpuOutlineString(bookmark.Kids))
+	}
+	return s
+}
+
+// pageSequence returns count pages starting at first.
+func pageSequence(first int, count int) []int {
+	pages := make([]int, count)
+	for i := range pages {
+		pages[i] = first + i
+	}
+	return pages
+}
+
+func TestBookmarksFromTOC(t *testing.T) {
+	tests := []struct {
+		name  string
+		toc   []edubase.TOCEntry
+		pages []int
+		want  string
+	}{
+		{
+			name:  "whole book",
+			toc:   testTOC,
+			pages: pageSequence(1, 38),
+			want:  "[1 Zahlen:1[1.1 Natürliche Zahlen:2][1.2 Brüche:8]][2 Geometrie:15][3 Algebra:27[3.1 Terme:28]]",
+		},
+		{
+			name:  "start page",
+			toc:   testTOC,
+			pages: pageSequence(5, 20),
+			want:  "[1.2 Brüche:4][2 Geometrie:11]",
+		},
+		{
+			name: "pages going backwards",
+			toc: []edubase.TOCEntry{
+				{Title: "B", Page: 5, Children: []edubase.TOCEntry{{Title: "B.1", Page: 3}}},
+				{Title: "A", Page: 2},
+			},
+			pages: pageSequence(1, 10),
+			want:  "[B:5[B.1:5]][A:5]",
+		},
+		{
+			name:  "page ranges",
+			toc:   testTOC,
+			pages: append(pageSequence(1, 3), pageSequence(15, 14)...),
+			want:  "[1 Zahlen:1[1.1 Natürliche Zahlen:2]][2 Geometrie:4][3 Algebra:16[3.1 Terme:17]]",
+		},
+		{
+			name:  "no table of contents",
+			toc:   nil,
+			pages: pageSequence(1, 10),
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := outlineString(bookmarksFromTOC(tt.toc, tt.pages))
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetOutline(t *testing.T) {
+	dir := t.TempDir()
+
+	images := []string{}
+	for i := 1; i <= 3; i++ {
+		filename := filepath.Join(dir, fmt.Sprintf("%d.jpeg", i))
+		writeTestJPEG(t, filename, 119, 168)
+		images = append(images, filename)
+	}
+
+	pdfPath := filepath.Join(dir, "book.pdf")
+	if err := pdfcpu.ImportImagesFile(images, pdfPath, nil, model.NewDefaultConfiguration()); err != nil {
+		t.Fatalf("could not create pdf: %v", err)
+	}
+
+	toc := []edubase.TOCEntry{
+		{Title: "Kapitel 1", Page: 1, Children: []edubase.TOCEntry{{Title: "Übungen", Page: 2}}},
+		{Title: "Kapitel 2", Page: 3},
+	}
+	err := updatePDF(pdfPath, func(ctx *model.Context) error {
+		return setOutline(ctx, bookmarksFromTOC(toc, pageSequence(1, 3)))
+	})
+	if err != nil {
+		t.Fatalf("set outline failed: %v", err)
+	}
+
+	f, err := os.Open(pdfPath)
+	if err != nil {
+		t.Fatalf("could not open pdf: %v", err)
+	}
+	defer f.Close()
+
+	bookmarks, err := pdfcpu.Bookmarks(f, nil)
+	if err != nil {
+		t.Fatalf("could not read bookmarks: %v", err)
+	}
+
+	if got, want := pdfcpuOutlineString(bookmarks), "[Kapitel 1:1[Übungen:2]][Kapitel 2:3]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestSetOutlineEmpty(t *testing.T) {
+	if err := setOutline(nil, nil); err != nil {
+		t.Errorf("set outline without bookmarks failed: %v", err)
+	}
+}
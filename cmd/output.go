@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+)
+
+// defaultOutputTemplate names the PDF of a book if --output is a directory.
+const defaultOutputTemplate = "{{.Title}}.pdf"
+
+var outputPath string = ""
+
+// outputFields are the fields of a book that an output template can use.
+// They are made safe for file names by sanitizeFilename, so a "/" in a
+// title cannot create a directory.
+type outputFields struct {
+	Id        int
+	Title     string
+	Version   string
+	Authors   string
+	Publisher string
+	ISBN      string
+}
+
+// parseOutput splits --output into the directory and the template of the
+// PDF files. Output containing "{{" is a template, which may contain
+// directories as well, output ending in ".pdf" is the path of the PDF, and
+// anything else is a directory.
+func parseOutput(output string) (string, *template.Template, error) {
+	dir, text := output, defaultOutputTemplate
+	if strings.Contains(output, "{{") || outputIsFile(output) {
+		dir, text = "", output
+	}
+
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid output template %q: %w", output, err)
+	}
+
+	return dir, tmpl, nil
+}
+
+// outputIsFile reports whether output is the path of a single PDF rather
+// than a directory or template.
+func outputIsFile(output string) bool {
+	return !strings.Contains(output, "{{") && strings.EqualFold(filepath.Ext(output), ".pdf")
+}
+
+// checkOutputBooks returns an error if --output is the path of a single PDF
+// but count books are imported, which would all be saved to that path.
+func checkOutputBooks(output string, count int) error {
+	if count > 1 && outputIsFile(output) {
+		return fmt.Errorf("--output %q is a single PDF, but %d books are imported: use a directory or a template like \"{{.Title}}.pdf\"", output, count)
+	}
+	return nil
+}
+
+// outputFile returns the path of the PDF of book for --output and creates
+// its directory. ".pdf" is added if the template has no such extension, and
+// the file name is made valid by sanitizeFilename.
+func outputFile(output string, book edubase.Book) (string, error) {
+	dir, tmpl, err := parseOutput(output)
+	if err != nil {
+		return "", err
+	}
+
+	fields := outputFields{
+		Id:        book.Id,
+		Title:     sanitizeFilename(book.Title),
+		Version:   sanitizeFilename(book.Version),
+		Authors:   sanitizeFilename(strings.Join(book.Authors, ", ")),
+		Publisher: sanitizeFilename(book.Publisher),
+		ISBN:      sanitizeFilename(book.ISBN),
+	}
+
+	var name strings.Builder
+	if err := tmpl.Execute(&name, fields); err != nil {
+		return "", fmt.Errorf("could not apply output template %q: %w", output, err)
+	}
+
+	if n := strings.TrimSpace(name.String()); n == "" || strings.HasSuffix(n, "/") || strings.HasSuffix(n, string(filepath.Separator)) {
+		return "", fmt.Errorf("output template %q gives no file name for book %d", output, book.Id)
+	}
+
+	// the fields are safe already, but text of the template may not be
+	nameDir, base := filepath.Split(name.String())
+	ext := filepath.Ext(base)
+	if !strings.EqualFold(ext, ".pdf") {
+		ext = ".pdf"
+	}
+	base = limitFilename(sanitizeFilename(strings.TrimSuffix(base, ext)), ext)
+
+	path := filepath.Join(dir, nameDir, base)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("could not create output directory: %w", err)
+	}
+
+	return path, nil
+}
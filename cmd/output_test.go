@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+)
+
+func TestOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	book := edubase.Book{
+		Id:      61532,
+		Title:   "Deutsch: Grammatik / Übungen",
+		Version: "2. Auflage",
+	}
+
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr string
+	}{
+		{name: "default", output: "", want: "Deutsch_ Grammatik _ Übungen.pdf"},
+		{name: "directory", output: filepath.Join(dir, "books"), want: filepath.Join(dir, "books", "Deutsch_ Grammatik _ Übungen.pdf")},
+		{name: "template", output: filepath.Join(dir, "{{.Id}}", "{{.Title}} ({{.Version}}).pdf"), want: filepath.Join(dir, "61532", "Deutsch_ Grammatik _ Übungen (2. Auflage).pdf")},
+		{name: "template without extension", output: filepath.Join(dir, "{{.Id}}"), want: filepath.Join(dir, "61532.pdf")},
+		{name: "reserved name", output: filepath.Join(dir, "{{.Id}}", "con. "), want: filepath.Join(dir, "61532", "con_.pdf")},
+		{name: "file", output: filepath.Join(dir, "pdfs", "book.PDF"), want: filepath.Join(dir, "pdfs", "book.PDF")},
+		{name: "invalid template", output: "{{.Title", wantErr: "invalid output template"},
+		{name: "unknown field", output: "{{.Name}}.pdf", wantErr: "could not apply output template"},
+		{name: "no file name", output: filepath.Join(dir, "{{.Publisher}}"), wantErr: "gives no file name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := outputFile(tt.output, book)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if info, err := os.Stat(filepath.Dir(got)); err != nil || !info.IsDir() {
+				t.Errorf("output directory %s was not created: %v", filepath.Dir(got), err)
+			}
+		})
+	}
+}
+
+func TestCheckOutputBooks(t *testing.T) {
+	if err := checkOutputBooks("book.pdf", 1); err != nil {
+		t.Errorf("one book to a file: unexpected error %v", err)
+	}
+	if err := checkOutputBooks("book.pdf", 2); err == nil || !strings.Contains(err.Error(), "single PDF") {
+		t.Errorf("two books to a file: got error %v, want it to contain %q", err, "single PDF")
+	}
+	if err := checkOutputBooks("books", 2); err != nil {
+		t.Errorf("two books to a directory: unexpected error %v", err)
+	}
+	if err := checkOutputBooks("{{.Id}}.pdf", 2); err != nil {
+		t.Errorf("two books to a template: unexpected error %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/pdf"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// pageLabels returns the labels of pages as shown by the reader. Pages
+// without a recorded label, e.g. captured by an older version, are labelled
+// with their page number.
+func pageLabels(m *manifest, pages []bookPage) []string {
+	labels := make([]string, 0, len(pages))
+	for _, page := range pages {
+		label := m.label(page.number)
+		if label == "" {
+			label = strconv.Itoa(page.number)
+		}
+		labels = append(labels, label)
+	}
+	return labels
+}
+
+// setPageLabels sets labels as page labels of a PDF, one label per page.
+// Nothing is changed if there are no labels.
+func setPageLabels(ctx *model.Context, labels []string) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	if len(labels) != ctx.PageCount {
+		return fmt.Errorf("got %d page labels, but PDF has %d pages", len(labels), ctx.PageCount)
+	}
+
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		return fmt.Errorf("could not read catalog: %w", err)
+	}
+
+	nums := types.Array{}
+	for _, r := range pdf.PageLabelRanges(labels) {
+		label := types.Dict{}
+		if r.Style != "" {
+			label.InsertName("S", r.Style)
+		}
+		if r.Prefix != "" {
+			label.Insert("P", types.NewHexLiteral([]byte(types.EncodeUTF16String(r.Prefix))))
+		}
+		if r.Style != "" && r.Start != 1 {
+			label.InsertInt("St", r.Start)
+		}
+		nums = append(nums, types.Integer(r.Page-1), label)
+	}
+	catalog.Update("PageLabels", types.Dict{"Nums": nums})
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pdfcpu "github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+func TestPageLabels(t *testing.T) {
+	m := newManifest(61532)
+	m.Pages[1] = manifestPage{Label: "i"}
+	m.Pages[2] = manifestPage{Label: "ii"}
+	m.Pages[3] = manifestPage{Label: "1"}
+
+	pages := []bookPage{{number: 1}, {number: 2}, {number: 3}, {number: 7}}
+	want := []string{"i", "ii", "1", "7"}
+	if got := pageLabels(m, pages); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestSetPageLabels(t *testing.T) {
+	dir := t.TempDir()
+
+	images := []string{}
+	for i := 1; i <= 4; i++ {
+		filename := filepath.Join(dir, fmt.Sprintf("%d.jpeg", i))
+		writeTestJPEG(t, filename, 119, 168)
+		images = append(images, filename)
+	}
+
+	pdfPath := filepath.Join(dir, "book.pdf")
+	if err := pdfcpu.ImportImagesFile(images, pdfPath, nil, model.NewDefaultConfiguration()); err != nil {
+		t.Fatalf("could not create pdf: %v", err)
+	}
+
+	addPageLabels := func(labels []string) error {
+		return updatePDF(pdfPath, func(ctx *model.Context) error {
+			return setPageLabels(ctx, labels)
+		})
+	}
+
+	if err := addPageLabels([]string{"i", "ii", "1"}); err == nil {
+		t.Errorf("adding fewer labels than pages should have failed")
+	}
+
+	if err := addPageLabels([]string{"Cover", "i", "1", "2"}); err != nil {
+		t.Fatalf("set page labels failed: %v", err)
+	}
+
+	ctx, err := pdfcpu.ReadContextFile(pdfPath)
+	if err != nil {
+		t.Fatalf("could not read pdf: %v", err)
+	}
+	if err := pdfcpu.ValidateContext(ctx); err != nil {
+		t.Fatalf("invalid pdf: %v", err)
+	}
+
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("could not read catalog: %v", err)
+	}
+	pageLabels := catalog.DictEntry("PageLabels")
+	if pageLabels == nil {
+		t.Fatalf("catalog has no page labels")
+	}
+
+	want := types.Array{
+		types.Integer(0), types.Dict{"P": types.HexLiteral("FEFF0043006F007600650072")},
+		types.Integer(1), types.Dict{"S": types.Name("r")},
+		types.Integer(2), types.Dict{"S": types.Name("D")},
+	}
+	if got := pageLabels.ArrayEntry("Nums"); got.String() != want.String() {
+		t.Errorf("got page labels %s, want %s", got, want)
+	}
+}
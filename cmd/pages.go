@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var pagesExpr string = ""
+
+// pageRange is a range of book pages. A last page of 0 means up to the end
+// of the book.
+type pageRange struct {
+	first int
+	last  int
+}
+
+// parsePageRanges parses a comma separated list of pages and ranges, e.g.
+// "1-10,15,40-". "-5" is short for "1-5".
+func parsePageRanges(expr string) ([]pageRange, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, errors.New("no pages given")
+	}
+
+	ranges := []pageRange{}
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("empty page range in %q", expr)
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+
+		var r pageRange
+		var err error
+		if from == "" {
+			r.first = 1
+		} else if r.first, err = parsePageNumber(from); err != nil {
+			return nil, fmt.Errorf("invalid page range %q: %w", part, err)
+		}
+
+		switch {
+		case !isRange:
+			r.last = r.first
+		case to == "":
+			if from == "" {
+				return nil, fmt.Errorf("invalid page range %q: give a first or last page", part)
+			}
+		default:
+			if r.last, err = parsePageNumber(to); err != nil {
+				return nil, fmt.Errorf("invalid page range %q: %w", part, err)
+			}
+			if r.last < r.first {
+				return nil, fmt.Errorf("invalid page range %q: last page is before first page", part)
+			}
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	return ranges, nil
+}
+
+func parsePageNumber(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a page number", s)
+	}
+	if n < 1 {
+		return 0, fmt.Errorf("page %d does not exist, pages start at 1", n)
+	}
+	return n, nil
+}
+
+// selectPages returns the pages of ranges in ascending order and without
+// duplicates. It fails for pages beyond the last page of the book.
+func selectPages(ranges []pageRange, totalPages int) ([]int, error) {
+	pages := []int{}
+	for _, r := range ranges {
+		last := r.last
+		if last == 0 {
+			last = totalPages
+		}
+		if r.first > totalPages || last > totalPages {
+			return nil, fmt.Errorf("page %d is beyond the last page %d of the book", max(r.first, last), totalPages)
+		}
+
+		for n := r.first; n <= last; n++ {
+			pages = append(pages, n)
+		}
+	}
+
+	slices.Sort(pages)
+	return slices.Compact(pages), nil
+}
+
+// importPageRanges returns the pages to import as given by --pages, or by
+// --start-page and --max-pages. Unlike --pages, the latter are cut off at
+// the end of the book.
+func importPageRanges(totalPages int) ([]pageRange, error) {
+	if pagesExpr != "" {
+		return parsePageRanges(pagesExpr)
+	}
+
+	if startPage < 1 {
+		return nil, fmt.Errorf("invalid start page %d: pages start at 1", startPage)
+	}
+	if maxPages == -1 {
+		return []pageRange{{first: startPage}}, nil
+	}
+	if maxPages < 1 {
+		return nil, fmt.Errorf("invalid max pages %d: must be at least 1 or -1 for all pages", maxPages)
+	}
+
+	last := startPage + maxPages - 1
+	if totalPages > 0 {
+		last = min(last, totalPages)
+	}
+	return []pageRange{{first: startPage, last: last}}, nil
+}
+
+// firstPage returns the lowest page of ranges.
+func firstPage(ranges []pageRange) int {
+	first := ranges[0].first
+	for _, r := range ranges[1:] {
+		first = min(first, r.first)
+	}
+	return first
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePageRanges(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    []pageRange
+		wantErr string
+	}{
+		{expr: "7", want: []pageRange{{7, 7}}},
+		{expr: "1-10,15,40-", want: []pageRange{{1, 10}, {15, 15}, {40, 0}}},
+		{expr: " 3 - 5 , -2", want: []pageRange{{3, 5}, {1, 2}}},
+		{expr: "", wantErr: "no pages given"},
+		{expr: "1,,3", wantErr: "empty page range"},
+		{expr: "-", wantErr: "give a first or last page"},
+		{expr: "a-3", wantErr: "\"a\" is not a page number"},
+		{expr: "0-3", wantErr: "pages start at 1"},
+		{expr: "10-5", wantErr: "last page is before first page"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := parsePageRanges(tt.expr)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectPages(t *testing.T) {
+	got, err := selectPages([]pageRange{{40, 0}, {1, 3}, {2, 4}, {15, 15}}, 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []int{1, 2, 3, 4, 15, 40, 41, 42}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, ranges := range [][]pageRange{{{1, 43}}, {{43, 0}}, {{50, 50}}} {
+		if _, err := selectPages(ranges, 42); err == nil || !strings.Contains(err.Error(), "beyond the last page 42") {
+			t.Errorf("selectPages(%v, 42): got error %v, want page beyond the end", ranges, err)
+		}
+	}
+}
+
+func TestImportPageRanges(t *testing.T) {
+	defer func(expr string, start int, max int) {
+		pagesExpr, startPage, maxPages = expr, start, max
+	}(pagesExpr, startPage, maxPages)
+
+	tests := []struct {
+		pages      string
+		start      int
+		max        int
+		totalPages int
+		want       []pageRange
+		wantErr    bool
+	}{
+		{start: 1, max: -1, totalPages: 20, want: []pageRange{{1, 0}}},
+		{start: 2, max: 10, totalPages: 20, want: []pageRange{{2, 11}}},
+		// max pages no longer reach past the end of the book
+		{start: 15, max: 10, totalPages: 20, want: []pageRange{{15, 20}}},
+		// the total is not known before the book is opened
+		{start: 15, max: 10, totalPages: 0, want: []pageRange{{15, 24}}},
+		{pages: "3,5-", start: 1, max: -1, totalPages: 20, want: []pageRange{{3, 3}, {5, 0}}},
+		{start: 0, max: -1, wantErr: true},
+		{start: 1, max: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		pagesExpr, startPage, maxPages = tt.pages, tt.start, tt.max
+
+		got, err := importPageRanges(tt.totalPages)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("pages %q, start %d, max %d: got error %v, want error %t", tt.pages, tt.start, tt.max, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("pages %q, start %d, max %d: got %v, want %v", tt.pages, tt.start, tt.max, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/pdf"
+	pdfcpu "github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/playwright-community/playwright-go"
+	"github.com/schollz/progressbar/v3"
+)
+
+const (
+	captureModeScreenshot = "screenshot"
+	captureModeSVG        = "svg"
+)
+
+// svgPageHTML wraps a page SVG into a document that prints as exactly one
+// PDF page of the size of the SVG.
+const svgPageHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<style>
+@page { margin: 0; }
+html, body { margin: 0; padding: 0; }
+svg { display: block; }
+</style>
+</head>
+<body>%s</body>
+</html>`
+
+// svgRenderer converts exported page SVGs into single-page vector PDFs. It
+// uses its own headless browser because Chromium can only print PDFs in
+// headless mode, while the reader may run in a visible window.
+type svgRenderer struct {
+	browser playwright.Browser
+	page    playwright.Page
+}
+
+func newSVGRenderer(pw *playwright.Playwright) (*svgRenderer, error) {
+	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
+		Headless: playwright.Bool(true),
+		Args: []string{
+			"--no-sandbox",
+			"--disable-setuid-sandbox",
+			"--disable-dev-shm-usage",
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to launch Chromium for rendering: %w", err)
+	}
+
+	page, err := browser.NewPage()
+	if err != nil {
+		_ = browser.Close()
+		return nil, fmt.Errorf("failed to create rendering page: %w", err)
+	}
+
+	return &svgRenderer{
+		browser: browser,
+		page:    page,
+	}, nil
+}
+
+// render prints svgFile as vector PDF to pdfFile.
+func (r *svgRenderer) render(ctx context.Context, svgFile string, pdfFile string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	svg, err := os.ReadFile(svgFile)
+	if err != nil {
+		return fmt.Errorf("could not read svg: %w", err)
+	}
+
+	if err := r.page.SetContent(fmt.Sprintf(svgPageHTML, svg), playwright.PageSetContentOptions{
+		WaitUntil: playwright.WaitUntilStateLoad,
+	}); err != nil {
+		return renderError(ctx, fmt.Errorf("could not load svg: %w", err))
+	}
+
+	// embedded fonts are loaded asynchronously
+	size, err := r.page.Evaluate(`async () => {
+		await document.fonts.ready;
+		const rect = document.querySelector('svg').getBoundingClientRect();
+		return [rect.width, rect.height];
+	}`)
+	if err != nil {
+		return renderError(ctx, fmt.Errorf("could not measure svg: %w", err))
+	}
+
+	dimensions, ok := size.([]interface{})
+	if !ok || len(dimensions) != 2 {
+		return fmt.Errorf("could not measure svg: unexpected result %v", size)
+	}
+
+	if _, err := r.page.PDF(playwright.PagePdfOptions{
+		Path:            playwright.String(pdfFile),
+		Width:           playwright.String(fmt.Sprintf("%vpx", dimensions[0])),
+		Height:          playwright.String(fmt.Sprintf("%vpx", dimensions[1])),
+		PrintBackground: playwright.Bool(true),
+		PageRanges:      playwright.String("1"),
+		Margin: &playwright.Margin{
+			Top:    playwright.String("0"),
+			Right:  playwright.String("0"),
+			Bottom: playwright.String("0"),
+			Left:   playwright.String("0"),
+		},
+	}); err != nil {
+		return renderError(ctx, fmt.Errorf("could not print pdf: %w", err))
+	}
+
+	return nil
+}
+
+// reset replaces the rendering page, which ends calls still pending on it.
+func (r *svgRenderer) reset() error {
+	page, err := r.browser.NewPage()
+	if err != nil {
+		return fmt.Errorf("failed to create rendering page: %w", err)
+	}
+
+	old := r.page
+	r.page = page
+	if err := old.Close(); err != nil {
+		return fmt.Errorf("could not close rendering page: %w", err)
+	}
+	return nil
+}
+
+func (r *svgRenderer) close() error {
+	return r.browser.Close()
+}
+
+// renderError reports ctx's error instead of err if ctx is done, as the
+// browser is closed on cancellation and err is merely a consequence.
+func renderError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// generateVectorPDF renders every page SVG as vector PDF page and merges the
+// pages into pdfPath. Bookmarks, page labels and metadata are added to the
+// merged PDF in one more pass.
+func generateVectorPDF(ctx context.Context, renderer *svgRenderer, svgFiles []string, bookmarks []pdf.Bookmark, labels []string, metadata pdf.Metadata, pdfPath string) error {
+	bar := progressbar.Default(int64(len(svgFiles)), "Generating PDF...")
+
+	pdfFiles := make([]string, 0, len(svgFiles))
+	for _, svgFile := range svgFiles {
+		pdfFile := svgFile[:len(svgFile)-len(".svg")] + ".pdf"
+		if err := renderer.render(ctx, svgFile, pdfFile); err != nil {
+			return fmt.Errorf("could not render %s: %w", svgFile, err)
+		}
+
+		pdfFiles = append(pdfFiles, pdfFile)
+		bar.Add(1)
+	}
+
+	if err := pdfcpu.MergeCreateFile(pdfFiles, pdfPath, false, model.NewDefaultConfiguration()); err != nil {
+		return fmt.Errorf("could not merge pages: %w", err)
+	}
+
+	return updatePDF(pdfPath, func(pdfCtx *model.Context) error {
+		// Validate the number of pages in the PDF
+		if pdfCtx.PageCount < len(svgFiles) {
+			return fmt.Errorf("❌ Failed to import all pages! Ebook Pages: %d | Pages in PDF: %d. Maybe delete PDF and try again.", len(svgFiles), pdfCtx.PageCount)
+		}
+		if pdfCtx.PageCount > len(svgFiles) {
+			return fmt.Errorf("❌ PDF has too many pages! Ebook Pages: %d | Pages in PDF: %d. Maybe delete PDF and try again.", len(svgFiles), pdfCtx.PageCount)
+		}
+
+		if err := setOutline(pdfCtx, bookmarks); err != nil {
+			return fmt.Errorf("could not add outline: %w", err)
+		}
+		if err := setPageLabels(pdfCtx, labels); err != nil {
+			return fmt.Errorf("could not add page labels: %w", err)
+		}
+		if err := setMetadata(pdfCtx, metadata); err != nil {
+			return fmt.Errorf("could not add metadata: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// generateRasterPDF writes the page images into pdfPath in a single pass.
+// textFiles holds the saved text of each page for the invisible text layer,
+// it is empty if the PDF should have none, and so are the page labels.
+func generateRasterPDF(ctx context.Context, imageFiles []string, textFiles []string, bookmarks []pdf.Bookmark, labels []string, metadata pdf.Metadata, pdfPath string) (err error) {
+	if len(textFiles) > 0 && len(textFiles) != len(imageFiles) {
+		return fmt.Errorf("got text for %d pages, but %d page images", len(textFiles), len(imageFiles))
+	}
+
+	f, err := os.Create(pdfPath)
+	if err != nil {
+		return fmt.Errorf("could not create PDF: %w", err)
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		// a partial PDF must not be mistaken for a finished one
+		if err != nil {
+			os.Remove(pdfPath)
+		}
+	}()
+
+	writer, err := pdf.NewWriter(f)
+	if err != nil {
+		return fmt.Errorf("could not write PDF: %w", err)
+	}
+
+	bar := progressbar.Default(int64(len(imageFiles)), "Generating PDF...")
+	for i, imageFile := range imageFiles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		textLayer := pdf.TextLayer{}
+		if len(textFiles) > 0 {
+			pageText, err := loadPageText(textFiles[i])
+			if err != nil {
+				return fmt.Errorf("could not load page text: %w", err)
+			}
+			textLayer = textLayerFromPageText(pageText)
+		}
+
+		if err := writer.AddJPEGPage(imageFile, textLayer); err != nil {
+			return fmt.Errorf("could not add %s: %w", imageFile, err)
+		}
+		bar.Add(1)
+	}
+
+	writer.SetOutline(bookmarks)
+	writer.SetPageLabels(labels)
+	writer.SetMetadata(metadata)
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("could not write PDF: %w", err)
+	}
+
+	return nil
+}
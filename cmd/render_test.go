@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase/edubasetest"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/pdf"
+	pdfcpu "github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+func TestGenerateVectorPDFOffline(t *testing.T) {
+	server := edubasetest.NewServer()
+	defer server.Close()
+
+	importProcess, err := newTestImportProcess(edubase.WithBaseURL(server.URL))
+	if err != nil {
+		t.Skipf("Skipping offline test: %v", err)
+	}
+	defer importProcess.close()
+
+	credentials := edubase.Credentials{
+		Email:    edubasetest.Email,
+		Password: edubasetest.Password,
+	}
+	if err := importProcess.loginProvider.Login(credentials, false); err != nil {
+		t.Fatalf("could not login: %v", err)
+	}
+
+	book := server.Books[0]
+	importProcess.bookProvider = edubase.NewBookProvider(importProcess.page, book.Id, edubase.WithBaseURL(server.URL))
+
+	dir := t.TempDir()
+	svgFiles := []string{}
+	for i := 1; i <= 3; i++ {
+		if err := importProcess.bookProvider.Open(i); err != nil {
+			t.Fatalf("could not open book: %v", err)
+		}
+
+		filename := fmt.Sprintf("%s/%d_%d.svg", dir, book.Id, i)
+		if err := importProcess.bookProvider.ExportSVG(filename); err != nil {
+			t.Fatalf("could not export svg: %v", err)
+		}
+		svgFiles = append(svgFiles, filename)
+	}
+
+	importProcess.renderer, err = newSVGRenderer(importProcess.pw)
+	if err != nil {
+		t.Fatalf("could not start renderer: %v", err)
+	}
+
+	pdfPath := filepath.Join(dir, "book.pdf")
+	labels := []string{"i", "1", "2"}
+	if err := generateVectorPDF(context.Background(), importProcess.renderer, svgFiles, nil, labels, pdf.Metadata{Title: book.Title}, pdfPath); err != nil {
+		t.Fatalf("could not generate vector pdf: %v", err)
+	}
+
+	pdfReadCtx, err := pdfcpu.ReadContextFile(pdfPath)
+	if err != nil {
+		t.Fatalf("could not read pdf: %v", err)
+	}
+	if pdfReadCtx.PageCount != len(svgFiles) {
+		t.Errorf("got %d pages in pdf, want %d", pdfReadCtx.PageCount, len(svgFiles))
+	}
+	// the document info is only read on validation
+	if err := pdfcpu.ValidateContext(pdfReadCtx); err != nil {
+		t.Fatalf("invalid pdf: %v", err)
+	}
+	if pdfReadCtx.Title != book.Title {
+		t.Errorf("got title %q, want %q", pdfReadCtx.Title, book.Title)
+	}
+}
+
+func TestGenerateRasterPDF(t *testing.T) {
+	dir := t.TempDir()
+
+	imageFiles := []string{}
+	textFiles := []string{}
+	for i := 1; i <= 3; i++ {
+		imageFile := filepath.Join(dir, fmt.Sprintf("1_%d.jpeg", i))
+		writeTestJPEG(t, imageFile, 119, 168)
+		imageFiles = append(imageFiles, imageFile)
+
+		textFile := filepath.Join(dir, fmt.Sprintf("1_%d.json", i))
+		pageText := edubase.PageText{
+			Width:  119,
+			Height: 168,
+			Runs:   []edubase.TextRun{{Text: fmt.Sprintf("Seite %d", i), X: 10, Y: 20, Width: 40, FontSize: 8}},
+		}
+		if err := savePageText(textFile, pageText); err != nil {
+			t.Fatalf("could not save page text: %v", err)
+		}
+		textFiles = append(textFiles, textFile)
+	}
+
+	bookmarks := []pdf.Bookmark{
+		{Title: "Kapitel 1", Page: 1, Kids: []pdf.Bookmark{{Title: "Übungen", Page: 2}}},
+		{Title: "Kapitel 2", Page: 3},
+	}
+
+	pdfPath := filepath.Join(dir, "book.pdf")
+	if err := generateRasterPDF(context.Background(), imageFiles, textFiles, bookmarks, nil, pdf.Metadata{}, pdfPath); err != nil {
+		t.Fatalf("could not generate pdf: %v", err)
+	}
+
+	pdfReadCtx, err := pdfcpu.ReadContextFile(pdfPath)
+	if err != nil {
+		t.Fatalf("could not read pdf: %v", err)
+	}
+	if pdfReadCtx.PageCount != len(imageFiles) {
+		t.Errorf("got %d pages in pdf, want %d", pdfReadCtx.PageCount, len(imageFiles))
+	}
+
+	f, err := os.Open(pdfPath)
+	if err != nil {
+		t.Fatalf("could not open pdf: %v", err)
+	}
+	defer f.Close()
+
+	read, err := pdfcpu.Bookmarks(f, nil)
+	if err != nil {
+		t.Fatalf("could not read bookmarks: %v", err)
+	}
+	if got, want := pdfcpuOutlineString(read), outlineString(bookmarks); got != want {
+		t.Errorf("got outline %s, want %s", got, want)
+	}
+}
+
+func TestGenerateRasterPDFCanceled(t *testing.T) {
+	dir := t.TempDir()
+
+	imageFile := filepath.Join(dir, "1_1.jpeg")
+	writeTestJPEG(t, imageFile, 10, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pdfPath := filepath.Join(dir, "book.pdf")
+	if err := generateRasterPDF(ctx, []string{imageFile}, nil, nil, nil, pdf.Metadata{}, pdfPath); !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+
+	if _, err := os.Stat(pdfPath); !os.IsNotExist(err) {
+		t.Errorf("partial pdf was not removed: %v", err)
+	}
+}
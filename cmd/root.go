@@ -1,18 +1,144 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
 	"os"
+	"path/filepath"
 
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+var baseURL string = edubase.DefaultBaseURL
+var sessionPath string = defaultConfigFile("session.json")
+var credentialsPath string = defaultConfigFile("credentials.json")
+var selectorsPath string = ""
+var selectors edubase.Selectors = edubase.DefaultSelectors()
+
 var rootCmd = &cobra.Command{
 	Use:   "edubase-to-pdf",
 	Short: "Convert Edubase to PDF",
 	Long:  `Convert Edubase to PDF.`,
 }
 
+func init() {
+	// settings of the config file and broken selectors are reported before
+	// anything else happens
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if _, err := applyConfig(cmd); err != nil {
+			return err
+		}
+		return loadSelectors()
+	}
+
+	rootCmd.PersistentFlags().StringVar(&baseURL, "base-url", baseURL, "Base URL of the Edubase instance, e.g. for staging or white-label instances.")
+	rootCmd.PersistentFlags().StringVar(&credentialsPath, "credentials-file", credentialsPath, "Encrypted credential store written by the credentials command.")
+	rootCmd.PersistentFlags().StringVar(&selectorsPath, "selectors", selectorsPath, "YAML file that overrides the selectors used to find elements of Edubase, e.g. after a UI change.")
+	rootCmd.PersistentFlags().StringVar(&sessionPath, "session", sessionPath, "Session file written by the login command. If it exists, it is used instead of logging in, unless credentials are passed as flags. Set to \"\" to always log in.")
+}
+
+// defaultConfigFile returns the path of the file name in the user's config
+// directory.
+func defaultConfigFile(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "edubase-to-pdf", name)
+}
+
+// sessionExists reports whether there is a session file to use.
+func sessionExists() bool {
+	if sessionPath == "" {
+		return false
+	}
+	_, err := os.Stat(sessionPath)
+	return err == nil
+}
+
+// useSession reports whether the session file is used instead of logging
+// in. Credentials passed as flags may belong to another account than the
+// session, so they always log in.
+func useSession(flags *pflag.FlagSet) bool {
+	if !sessionExists() {
+		return false
+	}
+	if credentialsGiven(flags) {
+		log.Printf("not using session %s, logging in with the given credentials", sessionPath)
+		return false
+	}
+	return true
+}
+
+// loadSelectors replaces the default selectors with the ones of
+// --selectors.
+func loadSelectors() error {
+	if selectorsPath == "" {
+		return nil
+	}
+
+	s, err := edubase.LoadSelectors(selectorsPath)
+	if err != nil {
+		return err
+	}
+	selectors = s
+
+	return nil
+}
+
+// providerOptions returns the options shared by all edubase providers.
+func providerOptions() []edubase.Option {
+	return []edubase.Option{
+		edubase.WithBaseURL(baseURL),
+		edubase.WithLoginMethod(edubase.LoginMethod(loginMethod)),
+		edubase.WithSelectors(selectors),
+	}
+}
+
+// Exit codes of the commands, so scripts can react to why a run failed.
+const (
+	exitCodeError              = 1
+	exitCodeInvalidCredentials = 3
+	exitCodeAccountLocked      = 4
+	exitCodeLoginPageChanged   = 5
+	exitCodeTimeout            = 6
+	exitCodeMFARequired        = 7
+)
+
+// exitWithError logs err and exits, with a hint and exit code for errors
+// the user can do something about.
+func exitWithError(err error) {
+	message, code := describeError(err)
+	log.Print(message)
+	os.Exit(code)
+}
+
+// describeError returns the message and exit code for err.
+func describeError(err error) (string, int) {
+	switch {
+	case errors.Is(err, edubase.ErrInvalidCredentials):
+		return fmt.Sprintf("%v: check your email and password, use --login-method microsoft for Microsoft accounts", err), exitCodeInvalidCredentials
+	case errors.Is(err, edubase.ErrAccountLocked):
+		return fmt.Sprintf("%v: unlock it on Edubase, e.g. by resetting your password, and try again later", err), exitCodeAccountLocked
+	case errors.Is(err, edubase.ErrLoginPageChanged):
+		return fmt.Sprintf("%v: Edubase may have changed its login page, please open an issue at https://github.com/michaelbeutler/edubase-to-pdf/issues", err), exitCodeLoginPageChanged
+	case errors.Is(err, edubase.ErrMFARequired):
+		return fmt.Sprintf("%v: log in with \"login --manual\" once and reuse the saved session, or use --manual", err), exitCodeMFARequired
+	case errors.Is(err, edubase.ErrTimeout):
+		return fmt.Sprintf("%v: Edubase did not answer in time, check your connection and try again", err), exitCodeTimeout
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Sprintf("timed out after %s (increase --timeout for large books): %v", timeout, err), exitCodeTimeout
+	case errors.Is(err, context.Canceled):
+		return fmt.Sprintf("canceled: %v", err), exitCodeError
+	default:
+		return err.Error(), exitCodeError
+	}
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
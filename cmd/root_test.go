@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+)
+
+func TestDescribeError(t *testing.T) {
+	tests := []struct {
+		err     error
+		code    int
+		message string
+	}{
+		{err: fmt.Errorf("could not login: %w", edubase.ErrInvalidCredentials), code: exitCodeInvalidCredentials, message: "check your email and password"},
+		{err: fmt.Errorf("could not login: %w", edubase.ErrAccountLocked), code: exitCodeAccountLocked, message: "unlock it"},
+		{err: fmt.Errorf("could not login: %w", edubase.ErrLoginPageChanged), code: exitCodeLoginPageChanged, message: "open an issue"},
+		{err: fmt.Errorf("could not login: %w", edubase.ErrMFARequired), code: exitCodeMFARequired, message: "login --manual"},
+		{err: fmt.Errorf("could not login: %w", edubase.ErrTimeout), code: exitCodeTimeout, message: "did not answer in time"},
+		{err: fmt.Errorf("could not get books: %w", context.DeadlineExceeded), code: exitCodeTimeout, message: "increase --timeout"},
+		{err: fmt.Errorf("could not get books: %w", context.Canceled), code: exitCodeError, message: "canceled"},
+		{err: errors.New("could not create pdf"), code: exitCodeError, message: "could not create pdf"},
+	}
+
+	for _, tt := range tests {
+		message, code := describeError(tt.err)
+		if code != tt.code {
+			t.Errorf("%v: got exit code %d, want %d", tt.err, code, tt.code)
+		}
+		if !strings.Contains(message, tt.message) {
+			t.Errorf("%v: got message %q, want it to contain %q", tt.err, message, tt.message)
+		}
+	}
+}
+
+func TestLoadSelectors(t *testing.T) {
+	defer func(path string, s edubase.Selectors) { selectorsPath, selectors = path, s }(selectorsPath, selectors)
+
+	selectorsPath = ""
+	if err := loadSelectors(); err != nil {
+		t.Fatalf("load without file failed: %v", err)
+	}
+	if selectors != edubase.DefaultSelectors() {
+		t.Errorf("expected default selectors without file")
+	}
+
+	selectorsPath = filepath.Join(t.TempDir(), "selectors.yaml")
+	if err := os.WriteFile(selectorsPath, []byte("version: 3\nreader:\n  toc: \"nav.toc\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadSelectors(); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if selectors.Reader.TOC != "nav.toc" {
+		t.Errorf("got toc selector %q, want %q", selectors.Reader.TOC, "nav.toc")
+	}
+
+	if err := os.WriteFile(selectorsPath, []byte("version: 3\nreader:\n  toc: \"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadSelectors(); err == nil {
+		t.Error("expected error for invalid selectors")
+	}
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/huh"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// selectBooks picks the books to import by --all, --book-id or --book-title,
+// or lets the user pick them if none of these is set. Of several --book-id,
+// the ones not in the library are returned as failed results, so that the
+// others are still imported.
+func selectBooks(ctx context.Context, books []edubase.Book) ([]edubase.Book, []importResult, error) {
+	switch {
+	case allBooks:
+		if len(books) == 0 {
+			return nil, nil, errors.New("there are no books in your library")
+		}
+		return books, nil, nil
+
+	case len(bookIds) > 0:
+		selected := []edubase.Book{}
+		missing := []importResult{}
+		seen := map[int]bool{}
+		for _, id := range bookIds {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			book, err := findBookById(books, id)
+			if err != nil {
+				missing = append(missing, importResult{book: edubase.Book{Id: id}, err: err})
+				continue
+			}
+			selected = append(selected, book)
+		}
+
+		// a single book fails like it always did
+		if len(seen) == 1 && len(missing) == 1 {
+			return nil, nil, missing[0].err
+		}
+		return selected, missing, nil
+
+	case bookTitle != "":
+		book, err := findBookByTitle(books, bookTitle)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []edubase.Book{book}, nil, nil
+	}
+
+	// books hold slices and cannot be option values, so select their index
+	options := make([]huh.Option[int], 0, len(books))
+	for i, book := range books {
+		options = append(options, huh.NewOption(book.Title, i))
+	}
+
+	indexes := []int{}
+	booksForm := huh.NewForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[int]().
+				Title("Books").
+				Description("Select with space or x, confirm with enter.").
+				Options(options...).
+				Validate(func(indexes []int) error {
+					if len(indexes) == 0 {
+						return errors.New("select at least one book")
+					}
+					return nil
+				}).
+				Value(&indexes),
+		),
+	)
+
+	if err := booksForm.RunWithContext(ctx); err != nil {
+		return nil, nil, fmt.Errorf("could not get book id: %w", err)
+	}
+
+	selected := make([]edubase.Book, 0, len(indexes))
+	for _, i := range indexes {
+		selected = append(selected, books[i])
+	}
+	return selected, nil, nil
+}
+
+// ambiguousBookError is returned when a book title matches more than one
+// book of the library.
+type ambiguousBookError struct {
+	title      string
+	candidates []edubase.Book
+}
+
+func (e *ambiguousBookError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "book title %q matches %d books, use a more specific title or --book-id:", e.title, len(e.candidates))
+	for _, book := range e.candidates {
+		fmt.Fprintf(&b, "\n  %d\t%s", book.Id, book.Title)
+	}
+	return b.String()
+}
+
+// findBookById returns the book of the library with the given id.
+func findBookById(books []edubase.Book, id int) (edubase.Book, error) {
+	for _, book := range books {
+		if book.Id == id {
+			return book, nil
+		}
+	}
+	return edubase.Book{}, fmt.Errorf("there is no book with id %d in your library", id)
+}
+
+// findBookByTitle returns the book of the library with the given title. An
+// exact match, ignoring case, wins; otherwise every word of title has to
+// start a word of the title of the book, ignoring case and accents.
+func findBookByTitle(books []edubase.Book, title string) (edubase.Book, error) {
+	exact := []edubase.Book{}
+	for _, book := range books {
+		if strings.EqualFold(strings.TrimSpace(book.Title), strings.TrimSpace(title)) {
+			exact = append(exact, book)
+		}
+	}
+
+	candidates := exact
+	if len(exact) == 0 {
+		words := titleWords(title)
+		for _, book := range books {
+			if containsWords(titleWords(book.Title), words) {
+				candidates = append(candidates, book)
+			}
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return edubase.Book{}, fmt.Errorf("there is no book matching %q in your library", title)
+	case 1:
+		return candidates[0], nil
+	default:
+		return edubase.Book{}, &ambiguousBookError{title: title, candidates: candidates}
+	}
+}
+
+// titleWords splits a title into lower case words without accents, so
+// "Übungen" and "ubungen" are the same word.
+func titleWords(title string) []string {
+	stripAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(stripAccents, title)
+	if err != nil {
+		folded = title
+	}
+
+	return strings.FieldsFunc(strings.ToLower(folded), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// containsWords reports whether each of words is the start of a word of the
+// title.
+func containsWords(titleWords []string, words []string) bool {
+	if len(words) == 0 {
+		return false
+	}
+
+	for _, word := range words {
+		found := false
+		for _, titleWord := range titleWords {
+			if strings.HasPrefix(titleWord, word) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+)
+
+var testBooks = []edubase.Book{
+	{Id: 58216, Title: "Mathematik 1"},
+	{Id: 58217, Title: "Mathematik 2"},
+	{Id: 61532, Title: "Deutsch: Grammatik / Übungen"},
+	{Id: 70001, Title: "Physik"},
+	{Id: 70002, Title: "Physik – Lösungen"},
+}
+
+func TestFindBookById(t *testing.T) {
+	book, err := findBookById(testBooks, 61532)
+	if err != nil {
+		t.Fatalf("find book failed: %v", err)
+	}
+	if book.Title != "Deutsch: Grammatik / Übungen" {
+		t.Errorf("got book %+v", book)
+	}
+
+	if _, err := findBookById(testBooks, 1); err == nil {
+		t.Errorf("finding a missing book should have failed")
+	}
+}
+
+func TestFindBookByTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		id    int
+	}{
+		{"Mathematik 1", 58216},
+		{"  mathematik 2 ", 58217},
+		{"grammatik ubungen", 61532},
+		{"deutsch", 61532},
+		{"Physik", 70001},
+		{"phys lös", 70002},
+	}
+
+	for _, tt := range tests {
+		book, err := findBookByTitle(testBooks, tt.title)
+		if err != nil {
+			t.Errorf("findBookByTitle(%q) failed: %v", tt.title, err)
+			continue
+		}
+		if book.Id != tt.id {
+			t.Errorf("findBookByTitle(%q) = %d; want %d", tt.title, book.Id, tt.id)
+		}
+	}
+}
+
+func TestFindBookByTitleAmbiguous(t *testing.T) {
+	_, err := findBookByTitle(testBooks, "mathe")
+
+	var ambiguous *ambiguousBookError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("got error %v, want ambiguous book error", err)
+	}
+	if len(ambiguous.candidates) != 2 {
+		t.Errorf("got %d candidates, want 2", len(ambiguous.candidates))
+	}
+	for _, want := range []string{"58216\tMathematik 1", "58217\tMathematik 2"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not list %q", err, want)
+		}
+	}
+}
+
+func TestFindBookByTitleNoMatch(t *testing.T) {
+	for _, title := range []string{"Chemie", "", "  "} {
+		if _, err := findBookByTitle(testBooks, title); err == nil {
+			t.Errorf("findBookByTitle(%q) should have failed", title)
+		}
+	}
+}
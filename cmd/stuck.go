@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"math/bits"
+	"os"
+	"path/filepath"
+)
+
+// stuckPageRetries is how often a page that looks like the reader did not
+// move on is opened again and captured before the import moves on.
+const stuckPageRetries = 2
+
+// hashSize is the width and height of the grid of an image hash.
+const hashSize = 32
+
+// maxHashDistance is the number of bits in which the hashes of two captures
+// of the same page may differ, e.g. by JPEG noise.
+const maxHashDistance = hashSize * hashSize / 64
+
+// minBrightnessStep is how much brighter a cell must be than the one right
+// of it to set its bit, so noise in empty areas does not flip bits.
+const minBrightnessStep = 2
+
+// imageHash is a perceptual hash of an image, one bit per cell of the grid.
+type imageHash [hashSize * hashSize / 64]uint64
+
+// distance returns the number of bits in which h and other differ.
+func (h imageHash) distance(other imageHash) int {
+	d := 0
+	for i := range h {
+		d += bits.OnesCount64(h[i] ^ other[i])
+	}
+	return d
+}
+
+// hashImage returns the difference hash of the image in file. The image is
+// shrunk to hashSize rows of hashSize+1 gray cells, and each bit tells if a
+// cell is brighter than the one right of it. Captures of the same page get
+// the same hash or nearly so, captures of different pages do not.
+func hashImage(file string) (imageHash, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return imageHash{}, fmt.Errorf("could not open image: %w", err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return imageHash{}, fmt.Errorf("could not decode image %s: %w", file, err)
+	}
+
+	var hash imageHash
+	bounds := img.Bounds()
+	for y := 0; y < hashSize; y++ {
+		left := cellBrightness(img, bounds, 0, y)
+		for x := 0; x < hashSize; x++ {
+			right := cellBrightness(img, bounds, x+1, y)
+			if left > right+minBrightnessStep {
+				bit := y*hashSize + x
+				hash[bit/64] |= 1 << (bit % 64)
+			}
+			left = right
+		}
+	}
+
+	return hash, nil
+}
+
+// cellBrightness returns the average gray of the cell at x, y of the grid
+// over bounds. Large cells are sampled at up to 8 by 8 points.
+func cellBrightness(img image.Image, bounds image.Rectangle, x int, y int) float64 {
+	cell := image.Rect(
+		bounds.Min.X+x*bounds.Dx()/(hashSize+1),
+		bounds.Min.Y+y*bounds.Dy()/hashSize,
+		bounds.Min.X+(x+1)*bounds.Dx()/(hashSize+1),
+		bounds.Min.Y+(y+1)*bounds.Dy()/hashSize,
+	)
+	stepX := max(1, cell.Dx()/8)
+	stepY := max(1, cell.Dy()/8)
+
+	sum, n := 0, 0
+	for py := cell.Min.Y; py < cell.Max.Y; py += stepY {
+		for px := cell.Min.X; px < cell.Max.X; px += stepX {
+			sum += int(color.GrayModel.Convert(img.At(px, py)).(color.Gray).Y)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return float64(sum) / float64(n)
+}
+
+// samePage tells if the captures in the files a and b show the same page.
+// Screenshots are compared by their hashes, page SVGs by their content.
+func samePage(a string, b string) (bool, error) {
+	if filepath.Ext(a) == ".svg" {
+		contentA, err := os.ReadFile(a)
+		if err != nil {
+			return false, fmt.Errorf("could not read page: %w", err)
+		}
+		contentB, err := os.ReadFile(b)
+		if err != nil {
+			return false, fmt.Errorf("could not read page: %w", err)
+		}
+		return bytes.Equal(contentA, contentB), nil
+	}
+
+	hashA, err := hashImage(a)
+	if err != nil {
+		return false, err
+	}
+	hashB, err := hashImage(b)
+	if err != nil {
+		return false, err
+	}
+	return hashA.distance(hashB) <= maxHashDistance, nil
+}
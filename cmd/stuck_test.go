@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestPage writes a JPEG that looks like a page of text, with words
+// placed by seed.
+func writeTestPage(t *testing.T, file string, seed int64, quality int) {
+	t.Helper()
+
+	img := image.NewGray(image.Rect(0, 0, 800, 1100))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	r := rand.New(rand.NewSource(seed))
+	for line := 80; line < 1020; line += 24 {
+		for x := 60; x < 740; {
+			word := 20 + r.Intn(80)
+			draw.Draw(img, image.Rect(x, line, min(x+word, 740), line+12), image.NewUniform(color.Gray{Y: 30}), image.Point{}, draw.Src)
+			x += word + 10
+		}
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := jpeg.Encode(f, img, &jpeg.Options{Quality: quality}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHashImage(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.jpeg")
+	again := filepath.Join(dir, "again.jpeg")
+	other := filepath.Join(dir, "other.jpeg")
+	writeTestPage(t, page, 1, 100)
+	writeTestPage(t, again, 1, 60)
+	writeTestPage(t, other, 2, 100)
+
+	hashes := map[string]imageHash{}
+	for _, file := range []string{page, again, other} {
+		hash, err := hashImage(file)
+		if err != nil {
+			t.Fatalf("hash of %s failed: %v", file, err)
+		}
+		hashes[file] = hash
+	}
+
+	if d := hashes[page].distance(hashes[again]); d > maxHashDistance {
+		t.Errorf("captures of the same page differ in %d bits, want at most %d", d, maxHashDistance)
+	}
+	if d := hashes[page].distance(hashes[other]); d <= maxHashDistance {
+		t.Errorf("different pages differ in %d bits, want more than %d", d, maxHashDistance)
+	}
+
+	if _, err := hashImage(filepath.Join(dir, "missing.jpeg")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestSamePage(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"1.svg": "<svg>1</svg>", "2.svg": "<svg>1</svg>", "3.svg": "<svg>2</svg>"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestPage(t, filepath.Join(dir, "1.jpeg"), 1, 100)
+	writeTestPage(t, filepath.Join(dir, "2.jpeg"), 1, 80)
+	writeTestPage(t, filepath.Join(dir, "3.jpeg"), 2, 100)
+
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"1.svg", "2.svg", true},
+		{"1.svg", "3.svg", false},
+		{"1.jpeg", "2.jpeg", true},
+		{"1.jpeg", "3.jpeg", false},
+	}
+
+	for _, tt := range tests {
+		got, err := samePage(filepath.Join(dir, tt.a), filepath.Join(dir, tt.b))
+		if err != nil {
+			t.Fatalf("samePage(%s, %s) failed: %v", tt.a, tt.b, err)
+		}
+		if got != tt.want {
+			t.Errorf("samePage(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	if _, err := samePage(filepath.Join(dir, "1.svg"), filepath.Join(dir, "missing.svg")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/pdf"
+)
+
+// savePageText writes the text of a page next to its screenshot.
+func savePageText(filename string, pageText edubase.PageText) error {
+	data, err := json.Marshal(pageText)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// loadPageText reads the text of a page written by savePageText.
+func loadPageText(filename string) (edubase.PageText, error) {
+	pageText := edubase.PageText{}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return pageText, err
+	}
+
+	err = json.Unmarshal(data, &pageText)
+	return pageText, err
+}
+
+// textLayerFromPageText converts the text of a page for the PDF writer.
+func textLayerFromPageText(pageText edubase.PageText) pdf.TextLayer {
+	textLayer := pdf.TextLayer{
+		Width:  pageText.Width,
+		Height: pageText.Height,
+		Runs:   make([]pdf.TextRun, 0, len(pageText.Runs)),
+	}
+
+	for _, run := range pageText.Runs {
+		textLayer.Runs = append(textLayer.Runs, pdf.TextRun{
+			Text:     run.Text,
+			X:        run.X,
+			Y:        run.Y,
+			Width:    run.Width,
+			FontSize: run.FontSize,
+		})
+	}
+
+	return textLayer
+}
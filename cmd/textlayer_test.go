@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/pdf"
+)
+
+// writeTestJPEG writes a white JPEG of the given size.
+func writeTestJPEG(t *testing.T, filename string, width, height int) {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("could not create jpeg: %v", err)
+	}
+	defer f.Close()
+
+	if err := jpeg.Encode(f, img, nil); err != nil {
+		t.Fatalf("could not encode jpeg: %v", err)
+	}
+}
+
+func TestSavePageText(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "1.json")
+
+	pageText := edubase.PageText{
+		Width:  595,
+		Height: 842,
+		Runs: []edubase.TextRun{
+			{Text: "Grüße (Kapitel 1)", X: 60, Y: 100, Width: 240, FontSize: 28},
+		},
+	}
+
+	if err := savePageText(filename, pageText); err != nil {
+		t.Fatalf("could not save page text: %v", err)
+	}
+
+	loaded, err := loadPageText(filename)
+	if err != nil {
+		t.Fatalf("could not load page text: %v", err)
+	}
+
+	if !reflect.DeepEqual(loaded, pageText) {
+		t.Errorf("got %+v, want %+v", loaded, pageText)
+	}
+}
+
+func TestTextLayerFromPageText(t *testing.T) {
+	pageText := edubase.PageText{
+		Width:  595,
+		Height: 842,
+		Runs: []edubase.TextRun{
+			{Text: "Übungen", X: 60, Y: 150, Width: 70, FontSize: 16},
+		},
+	}
+
+	want := pdf.TextLayer{
+		Width:  595,
+		Height: 842,
+		Runs: []pdf.TextRun{
+			{Text: "Übungen", X: 60, Y: 150, Width: 70, FontSize: 16},
+		},
+	}
+
+	if got := textLayerFromPageText(pageText); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,50 @@
+// Package atomicfile writes files so that an interrupted or failed write
+// never leaves a partial file behind: the file is written next to its path
+// and only moved there once it is complete.
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Write lets write create the file at a temporary path next to path and
+// moves it to path once write succeeded. An existing file at path is only
+// replaced by a complete one, and the temporary file is removed on every
+// failure. The file gets the permissions perm.
+func Write(path string, perm os.FileMode, write func(tmpPath string) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not create temporary file: %w", err)
+	}
+	tmpPath := f.Name()
+
+	err = f.Chmod(perm)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("could not create temporary file: %w", err)
+	}
+
+	if err := write(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("could not replace %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// WriteFile writes data to path like os.WriteFile, but as Write does.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return Write(path, perm, func(tmpPath string) error {
+		return os.WriteFile(tmpPath, data, perm)
+	})
+}
@@ -0,0 +1,104 @@
+package atomicfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "book.pdf")
+
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a failed write keeps the old file and leaves no temporary file
+	failure := errors.New("could not render")
+	err := Write(path, 0644, func(tmpPath string) error {
+		if err := os.WriteFile(tmpPath, []byte("partial"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("got error %v, want %v", err, failure)
+	}
+	assertFiles(t, dir, "book.pdf")
+	if data, _ := os.ReadFile(path); string(data) != "old" {
+		t.Errorf("failed write changed the file to %q", data)
+	}
+
+	err = Write(path, 0644, func(tmpPath string) error {
+		if filepath.Dir(tmpPath) != dir {
+			t.Errorf("temporary file %s is not next to %s", tmpPath, path)
+		}
+		return os.WriteFile(tmpPath, []byte("new"), 0644)
+	})
+	if err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	assertFiles(t, dir, "book.pdf")
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("got %q, want %q", data, "new")
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0644 {
+		t.Errorf("got mode %v, want %v", info.Mode().Perm(), os.FileMode(0644))
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.json")
+
+	if err := WriteFile(path, []byte("{}"), 0600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	assertFiles(t, dir, "session.json")
+	if data, _ := os.ReadFile(path); string(data) != "{}" {
+		t.Errorf("got %q, want %q", data, "{}")
+	}
+	if info, err := os.Stat(path); runtime.GOOS != "windows" && (err != nil || info.Mode().Perm() != 0600) {
+		t.Errorf("got mode %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+}
+
+func TestWriteFileRenameFails(t *testing.T) {
+	dir := t.TempDir()
+
+	// a directory at the path cannot be replaced by a file
+	path := filepath.Join(dir, "store.json")
+	if err := os.Mkdir(path, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "keep"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFile(path, []byte("secret"), 0600); err == nil {
+		t.Errorf("expected error when the path is a directory")
+	}
+	assertFiles(t, dir, "store.json")
+}
+
+// assertFiles checks that dir holds exactly the files names.
+func assertFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, entry := range entries {
+		got = append(got, entry.Name())
+	}
+	if strings.Join(got, ",") != strings.Join(names, ",") {
+		t.Errorf("got files %v, want %v", got, names)
+	}
+}
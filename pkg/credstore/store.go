@@ -0,0 +1,223 @@
+// Package credstore keeps Edubase credentials in a file encrypted with a
+// passphrase, so they do not have to be written into scripts in plain text.
+//
+// The key is derived from the passphrase with Argon2id and the credentials
+// of all profiles are encrypted together with AES-256-GCM, so without the
+// passphrase not even the profile names can be read.
+package credstore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/atomicfile"
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+	"golang.org/x/crypto/argon2"
+)
+
+const fileVersion = 1
+
+// Argon2id parameters for new files, as recommended by RFC 9106 for
+// memory constrained environments. They are stored in the file, so they can
+// be raised later without breaking existing files.
+const (
+	kdfTime    = 3
+	kdfMemory  = 64 * 1024
+	kdfThreads = 4
+	keyLength  = 32
+	saltLength = 16
+)
+
+var (
+	// ErrWrongPassphrase is returned when the file cannot be decrypted,
+	// either because the passphrase is wrong or the file was modified.
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupt credential store")
+	// ErrProfileNotFound is returned for profiles that are not in the store.
+	ErrProfileNotFound = errors.New("profile not found")
+)
+
+// Store holds the credentials of all profiles of a credential store file.
+type Store struct {
+	path       string
+	passphrase []byte
+	profiles   map[string]edubase.Credentials
+}
+
+type file struct {
+	Version int    `json:"version"`
+	KDF     kdf    `json:"kdf"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+type kdf struct {
+	Algorithm string `json:"algorithm"`
+	Salt      []byte `json:"salt"`
+	Time      uint32 `json:"time"`
+	Memory    uint32 `json:"memory"`
+	Threads   uint8  `json:"threads"`
+}
+
+// Load decrypts the store at path with passphrase. If there is no file at
+// path yet, it returns an empty store that is written on Save.
+func Load(path string, passphrase []byte) (*Store, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase is empty")
+	}
+
+	s := &Store{
+		path:       path,
+		passphrase: passphrase,
+		profiles:   map[string]edubase.Credentials{},
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read credential store: %w", err)
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("could not parse credential store: %w", err)
+	}
+	if f.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported credential store version %d", f.Version)
+	}
+	if f.KDF.Algorithm != "argon2id" {
+		return nil, fmt.Errorf("unsupported key derivation %q", f.KDF.Algorithm)
+	}
+
+	aead, err := newAEAD(passphrase, f.KDF)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+
+	plaintext, err := aead.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	if err := json.Unmarshal(plaintext, &s.profiles); err != nil {
+		return nil, fmt.Errorf("could not parse credentials: %w", err)
+	}
+
+	return s, nil
+}
+
+// Exists reports whether there is a store file at path.
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Get returns the credentials of profile.
+func (s *Store) Get(profile string) (edubase.Credentials, error) {
+	credentials, ok := s.profiles[profile]
+	if !ok {
+		return edubase.Credentials{}, fmt.Errorf("%w: %q", ErrProfileNotFound, profile)
+	}
+	return credentials, nil
+}
+
+// Set adds or replaces the credentials of profile. Call Save to write them.
+func (s *Store) Set(profile string, credentials edubase.Credentials) {
+	s.profiles[profile] = credentials
+}
+
+// Delete removes profile. Call Save to write the change.
+func (s *Store) Delete(profile string) error {
+	if _, ok := s.profiles[profile]; !ok {
+		return fmt.Errorf("%w: %q", ErrProfileNotFound, profile)
+	}
+	delete(s.profiles, profile)
+	return nil
+}
+
+// Profiles returns the names of all profiles, sorted.
+func (s *Store) Profiles() []string {
+	profiles := make([]string, 0, len(s.profiles))
+	for profile := range s.profiles {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+	return profiles
+}
+
+// Save encrypts the store with a new salt and nonce and writes it. The file
+// is only readable by the current user.
+func (s *Store) Save() error {
+	plaintext, err := json.Marshal(s.profiles)
+	if err != nil {
+		return fmt.Errorf("could not encode credentials: %w", err)
+	}
+
+	k := kdf{
+		Algorithm: "argon2id",
+		Salt:      make([]byte, saltLength),
+		Time:      kdfTime,
+		Memory:    kdfMemory,
+		Threads:   kdfThreads,
+	}
+	if _, err := rand.Read(k.Salt); err != nil {
+		return fmt.Errorf("could not create salt: %w", err)
+	}
+
+	aead, err := newAEAD(s.passphrase, k)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("could not create nonce: %w", err)
+	}
+
+	data, err := json.MarshalIndent(file{
+		Version: fileVersion,
+		KDF:     k,
+		Nonce:   nonce,
+		Data:    aead.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode credential store: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("could not create credential store directory: %w", err)
+	}
+
+	// a failed write must not destroy the credentials already stored
+	if err := atomicfile.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("could not write credential store: %w", err)
+	}
+
+	return nil
+}
+
+func newAEAD(passphrase []byte, k kdf) (cipher.AEAD, error) {
+	if k.Time == 0 || k.Memory == 0 || k.Threads == 0 || len(k.Salt) == 0 {
+		return nil, errors.New("invalid key derivation parameters")
+	}
+
+	key := argon2.IDKey(passphrase, k.Salt, k.Time, k.Memory, k.Threads, keyLength)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,131 @@
+package credstore
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase"
+)
+
+var testCredentials = edubase.Credentials{Email: "student@example.com", Password: "s3cret"}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "credentials.json")
+
+	s, err := Load(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("load of missing store failed: %v", err)
+	}
+	if len(s.Profiles()) != 0 {
+		t.Errorf("expected empty store, got %v", s.Profiles())
+	}
+
+	s.Set("school", testCredentials)
+	s.Set("default", edubase.Credentials{Email: "other@example.com", Password: "other"})
+	if err := s.Save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("got permissions %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plain := range []string{"school", testCredentials.Email, testCredentials.Password} {
+		if strings.Contains(string(data), plain) {
+			t.Errorf("store contains %q in plain text", plain)
+		}
+	}
+
+	s, err = Load(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if !reflect.DeepEqual(s.Profiles(), []string{"default", "school"}) {
+		t.Errorf("got profiles %v", s.Profiles())
+	}
+	credentials, err := s.Get("school")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if credentials != testCredentials {
+		t.Errorf("got %+v, want %+v", credentials, testCredentials)
+	}
+
+	if err := s.Delete("school"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := s.Get("school"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrProfileNotFound)
+	}
+	if err := s.Delete("school"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("got error %v, want %v", err, ErrProfileNotFound)
+	}
+}
+
+func TestLoadWrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+
+	s, err := Load(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Set("default", testCredentials)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("got error %v, want %v", err, ErrWrongPassphrase)
+	}
+
+	if _, err := Load(path, nil); err == nil {
+		t.Error("expected error for empty passphrase")
+	}
+}
+
+func TestLoadModified(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+
+	s, err := Load(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Set("default", testCredentials)
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		t.Fatal(err)
+	}
+	f.Data[0] ^= 0xff
+	data, err = json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path, []byte("passphrase")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("got error %v, want %v", err, ErrWrongPassphrase)
+	}
+}
@@ -1,9 +1,11 @@
 package edubase
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/playwright-community/playwright-go"
@@ -14,36 +16,61 @@ type BookProvider struct {
 	baseURL      string
 	bookId       int
 	initialDelay time.Duration
+	selectors    Selectors
 }
 
-func NewBookProvider(page playwright.Page, id int) *BookProvider {
+func NewBookProvider(page playwright.Page, id int, opts ...Option) *BookProvider {
+	c := newConfig(opts)
+
 	return &BookProvider{
 		page:         page,
-		baseURL:      "https://app.edubase.ch",
+		baseURL:      c.BaseURL,
 		bookId:       id,
-		initialDelay: 500 * time.Millisecond,
+		initialDelay: c.InitialDelay,
+		selectors:    c.Selectors,
 	}
 }
 
 func (b *BookProvider) Open(page int) error {
-	time.Sleep(b.initialDelay)
+	return b.OpenContext(context.Background(), page)
+}
+
+// OpenContext is like Open but returns early when ctx is done.
+func (b *BookProvider) OpenContext(ctx context.Context, page int) error {
+	if err := Sleep(ctx, b.initialDelay); err != nil {
+		return fmt.Errorf("could not open book: %w", err)
+	}
 
 	// navigate to book
-	if _, err := b.page.Goto(fmt.Sprintf("%s/#doc/%d/%d", b.baseURL, b.bookId, page), playwright.PageGotoOptions{
-		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
+	if err := await(ctx, func() error {
+		_, err := b.page.Goto(fmt.Sprintf("%s/#doc/%d/%d", b.baseURL, b.bookId, page), playwright.PageGotoOptions{
+			WaitUntil: playwright.WaitUntilStateDomcontentloaded,
+		})
+		return err
 	}); err != nil {
-		return fmt.Errorf("could not open book: %v", err)
+		return fmt.Errorf("could not open book: %w", err)
 	}
 
 	return nil
 }
 
 func (b *BookProvider) GetTotalPages() (int, error) {
-	time.Sleep(b.initialDelay)
+	return b.GetTotalPagesContext(context.Background())
+}
 
-	rawTotalPages, err := b.page.Locator("#pagination > div > span").Last().InnerText()
-	if err != nil {
-		return 0, fmt.Errorf("could not get max page number: %v", err)
+// GetTotalPagesContext is like GetTotalPages but returns early when ctx is
+// done.
+func (b *BookProvider) GetTotalPagesContext(ctx context.Context) (int, error) {
+	if err := Sleep(ctx, b.initialDelay); err != nil {
+		return 0, fmt.Errorf("could not get max page number: %w", err)
+	}
+
+	var rawTotalPages string
+	if err := await(ctx, func() (err error) {
+		rawTotalPages, err = b.page.Locator(b.selectors.Reader.TotalPages).Last().InnerText()
+		return err
+	}); err != nil {
+		return 0, fmt.Errorf("could not get max page number: %w", err)
 	}
 
 	re := regexp.MustCompile("[0-9]+")
@@ -61,18 +88,74 @@ func (b *BookProvider) GetTotalPages() (int, error) {
 	return totalPages, nil
 }
 
+// GetPageLabel returns the page number the reader shows for the current
+// page, e.g. "iv" for a page of the front matter.
+func (b *BookProvider) GetPageLabel() (string, error) {
+	return b.GetPageLabelContext(context.Background())
+}
+
+// GetPageLabelContext is like GetPageLabel but returns early when ctx is
+// done.
+func (b *BookProvider) GetPageLabelContext(ctx context.Context) (string, error) {
+	var label string
+	if err := await(ctx, func() (err error) {
+		label, err = b.page.Locator(b.selectors.Reader.PageLabel).First().InnerText()
+		return err
+	}); err != nil {
+		return "", fmt.Errorf("could not get page label: %w", err)
+	}
+
+	return strings.TrimSpace(label), nil
+}
+
+var readerURL = regexp.MustCompile(`#doc/([0-9]+)/([0-9]+)`)
+
+// GetCurrentPage returns the number of the page the reader is at, as given
+// by the URL of the reader. The URL changes with the page, so it tells if
+// the reader really moved to the next page.
+func (b *BookProvider) GetCurrentPage() (int, error) {
+	url := b.page.URL()
+
+	m := readerURL.FindStringSubmatch(url)
+	if m == nil {
+		return 0, fmt.Errorf("could not find page number in URL %s", url)
+	}
+	if m[1] != strconv.Itoa(b.bookId) {
+		return 0, fmt.Errorf("reader is at book %s instead of book %d", m[1], b.bookId)
+	}
+
+	page, err := strconv.Atoi(m[2])
+	if err != nil {
+		return 0, fmt.Errorf("could not convert page number: %w", err)
+	}
+
+	return page, nil
+}
+
 func (b *BookProvider) NextPage() error {
+	return b.NextPageContext(context.Background())
+}
+
+// NextPageContext is like NextPage but returns early when ctx is done.
+func (b *BookProvider) NextPageContext(ctx context.Context) error {
 	// navigate to next page
-	nextPageButton := b.page.Locator("[data-action='next-page']").First()
+	nextPageButton := b.page.Locator(b.selectors.Reader.NextPageButton).First()
 
-	if err := nextPageButton.Click(); err != nil {
-		return fmt.Errorf("could not click next page button: %v", err)
+	if err := await(ctx, func() error {
+		return nextPageButton.Click()
+	}); err != nil {
+		return fmt.Errorf("could not click next page button: %w", err)
 	}
 
 	return nil
 }
 
 func (b *BookProvider) Screenshot(filename string) error {
+	return b.ScreenshotContext(context.Background(), filename)
+}
+
+// ScreenshotContext is like Screenshot but returns early when ctx is done.
+func (b *BookProvider) ScreenshotContext(ctx context.Context, filename string) error {
 	// check if filename is empty
 	if filename == "" {
 		return fmt.Errorf("filename is empty")
@@ -84,15 +167,18 @@ func (b *BookProvider) Screenshot(filename string) error {
 	}
 
 	// get .doc-page element
-	docPage := b.page.Locator(".lu-page-svg-container").First()
+	docPage := b.page.Locator(b.selectors.Reader.PageContainer).First()
 
 	// take screenshot
-	if _, err := docPage.Screenshot(playwright.LocatorScreenshotOptions{
-		Path:    playwright.String(filename),
-		Quality: playwright.Int(100),
-		Type:    playwright.ScreenshotTypeJpeg,
+	if err := await(ctx, func() error {
+		_, err := docPage.Screenshot(playwright.LocatorScreenshotOptions{
+			Path:    playwright.String(filename),
+			Quality: playwright.Int(100),
+			Type:    playwright.ScreenshotTypeJpeg,
+		})
+		return err
 	}); err != nil {
-		return fmt.Errorf("could not create screenshot: %v", err)
+		return fmt.Errorf("could not create screenshot: %w", err)
 	}
 
 	return nil
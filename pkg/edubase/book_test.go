@@ -1,9 +1,15 @@
 package edubase
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/playwright-community/playwright-go"
 )
 
 func TestOpenBookAtPage1(t *testing.T) {
@@ -159,3 +165,156 @@ func TestScreenshot(t *testing.T) {
 		}
 	}()
 }
+
+func TestGetTotalPagesOffline(t *testing.T) {
+	server, page := setupTestServerWithLogin(t)
+	book := server.Books[0]
+
+	bookProvider := NewBookProvider(page, book.Id, WithBaseURL(server.URL))
+	if err := bookProvider.Open(1); err != nil {
+		t.Fatalf("failed to open book: %v", err)
+	}
+
+	totalPages, err := bookProvider.GetTotalPages()
+	if err != nil {
+		t.Fatalf("get total pages failed: %v", err)
+	}
+
+	if totalPages != book.Pages {
+		t.Errorf("unexpected total number of pages: got %d, want %d", totalPages, book.Pages)
+	}
+}
+
+func TestNextPageOffline(t *testing.T) {
+	server, page := setupTestServerWithLogin(t)
+
+	bookProvider := NewBookProvider(page, server.Books[0].Id, WithBaseURL(server.URL))
+	if err := bookProvider.Open(1); err != nil {
+		t.Fatalf("failed to open book: %v", err)
+	}
+
+	if err := bookProvider.NextPage(); err != nil {
+		t.Fatalf("failed to get next page: %v", err)
+	}
+
+	currentPage := page.Locator("#pagination > div > div > span").First()
+	if err := playwright.NewPlaywrightAssertions().Locator(currentPage).ToHaveText("2"); err != nil {
+		t.Errorf("unexpected current page number: %v", err)
+	}
+}
+
+func TestScreenshotOffline(t *testing.T) {
+	server, page := setupTestServerWithLogin(t)
+
+	bookProvider := NewBookProvider(page, server.Books[0].Id, WithBaseURL(server.URL))
+	if err := bookProvider.Open(1); err != nil {
+		t.Fatalf("failed to open book: %v", err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "page.jpeg")
+	if err := bookProvider.Screenshot(filename); err != nil {
+		t.Fatalf("screenshot failed: %v", err)
+	}
+
+	if _, err := os.Stat(filename); err != nil {
+		t.Errorf("screenshot file does not exist: %v", err)
+	}
+}
+
+func TestExportSVGWrongExtension(t *testing.T) {
+	bookProvider := NewBookProvider(nil, 58216)
+
+	for _, filename := range []string{"", "page.jpeg", "page.svg.txt"} {
+		if err := bookProvider.ExportSVG(filename); err == nil {
+			t.Errorf("ExportSVG(%q) should have failed", filename)
+		}
+	}
+}
+
+func TestExportSVGOffline(t *testing.T) {
+	server, page := setupTestServerWithLogin(t)
+	book := server.Books[0]
+
+	bookProvider := NewBookProvider(page, book.Id, WithBaseURL(server.URL))
+	if err := bookProvider.Open(1); err != nil {
+		t.Fatalf("failed to open book: %v", err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "page.svg")
+	if err := bookProvider.ExportSVG(filename); err != nil {
+		t.Fatalf("export svg failed: %v", err)
+	}
+
+	svg, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("could not read svg: %v", err)
+	}
+
+	for _, want := range []string{"<svg", book.Title, "data:image/png;base64,"} {
+		if !strings.Contains(string(svg), want) {
+			t.Errorf("svg does not contain %q", want)
+		}
+	}
+}
+
+func TestGetPageLabelOffline(t *testing.T) {
+	server, page := setupTestServerWithLogin(t)
+	book := server.Books[1]
+
+	bookProvider := NewBookProvider(page, book.Id, WithBaseURL(server.URL))
+	for _, n := range []int{1, 2, 3} {
+		if err := bookProvider.Open(n); err != nil {
+			t.Fatalf("failed to open book at page %d: %v", n, err)
+		}
+
+		// wait for the reader to show the page
+		currentPage := page.Locator("#pagination > div > div > span").First()
+		if err := playwright.NewPlaywrightAssertions().Locator(currentPage).ToHaveText(book.PageLabel(n)); err != nil {
+			t.Fatalf("reader does not show page %d: %v", n, err)
+		}
+
+		label, err := bookProvider.GetPageLabel()
+		if err != nil {
+			t.Fatalf("get page label failed: %v", err)
+		}
+		if label != book.PageLabel(n) {
+			t.Errorf("page %d: got label %q, want %q", n, label, book.PageLabel(n))
+		}
+	}
+}
+
+func TestGetCurrentPageOffline(t *testing.T) {
+	server, page := setupTestServerWithLogin(t)
+	book := server.Books[0]
+
+	bookProvider := NewBookProvider(page, book.Id, WithBaseURL(server.URL))
+	if err := bookProvider.Open(4); err != nil {
+		t.Fatalf("failed to open book: %v", err)
+	}
+
+	for _, want := range []int{4, 5, 6} {
+		// wait for the reader to move to the page
+		pageURL := regexp.MustCompile(fmt.Sprintf("#doc/%d/%d$", book.Id, want))
+		if err := playwright.NewPlaywrightAssertions().Page(page).ToHaveURL(pageURL); err != nil {
+			t.Fatalf("reader is not at page %d: %v", want, err)
+		}
+
+		currentPage, err := bookProvider.GetCurrentPage()
+		if err != nil {
+			t.Fatalf("get current page failed: %v", err)
+		}
+		if currentPage != want {
+			t.Errorf("got page %d, want %d", currentPage, want)
+		}
+
+		if err := bookProvider.NextPage(); err != nil {
+			t.Fatalf("failed to navigate to next page: %v", err)
+		}
+	}
+
+	// another book is not the page of this one
+	other := NewBookProvider(page, server.Books[1].Id, WithBaseURL(server.URL))
+	if _, err := other.GetCurrentPage(); err == nil {
+		t.Error("expected error for the page of another book")
+	}
+}
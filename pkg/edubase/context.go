@@ -0,0 +1,46 @@
+package edubase
+
+import (
+	"context"
+	"time"
+)
+
+// Sleep pauses for d or until ctx is done, whichever happens first.
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// await runs fn, usually a blocking playwright call, and returns ctx's error
+// as soon as ctx is done. playwright calls cannot be interrupted, so fn keeps
+// running in the background until it returns on its own, e.g. because the
+// caller closed the browser.
+func await(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		// the call most likely failed because the browser was closed on
+		// cancellation, report the cause instead
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+}
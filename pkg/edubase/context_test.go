@@ -0,0 +1,61 @@
+package edubase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSleepCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if err := Sleep(ctx, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Errorf("Sleep with canceled context = %v; want %v", err, context.Canceled)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Sleep did not return early")
+	}
+
+	if err := Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("Sleep = %v; want nil", err)
+	}
+}
+
+func TestAwait(t *testing.T) {
+	want := errors.New("playwright error")
+	if err := await(context.Background(), func() error { return want }); err != want {
+		t.Errorf("await = %v; want %v", err, want)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	block := make(chan struct{})
+	defer close(block)
+
+	err := await(ctx, func() error {
+		<-block
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("await with expired context = %v; want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWaitForLoginSuccessCanceled(t *testing.T) {
+	server, page := setupTestServer(t)
+
+	loginProvider := NewLoginProvider(page, WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	// nobody completes the manual login, so only the deadline can end it
+	err := loginProvider.LoginContext(ctx, Credentials{}, true)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("manual login = %v; want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,112 @@
+package edubase
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ErrNotTerminal is returned when the password should be typed in but the
+// input is not a terminal, so it could not be hidden.
+var ErrNotTerminal = errors.New("input is not a terminal")
+
+// GetCredentials asks for the email and password on the terminal. The
+// password is not shown while it is typed.
+func GetCredentials() (Credentials, error) {
+	email, err := PromptEmail(os.Stdin, os.Stderr)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	password, err := PromptPassword(os.Stdin, os.Stderr)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	return Credentials{Email: email, Password: password}, nil
+}
+
+// PromptEmail writes a prompt to out and reads the email from in.
+func PromptEmail(in io.Reader, out io.Writer) (string, error) {
+	fmt.Fprint(out, "Email: ")
+	email, err := readLine(in)
+	if err != nil {
+		return "", fmt.Errorf("could not read email: %w", err)
+	}
+
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", errors.New("email is empty")
+	}
+
+	return email, nil
+}
+
+// PromptPassword writes a prompt to out and reads the password from the
+// terminal in without echoing it. It returns ErrNotTerminal if in is not a
+// terminal.
+func PromptPassword(in *os.File, out io.Writer) (string, error) {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("could not read password: %w", ErrNotTerminal)
+	}
+
+	fmt.Fprint(out, "Password: ")
+	password, err := term.ReadPassword(fd)
+	// the newline typed by the user is not echoed either
+	fmt.Fprintln(out)
+	if err != nil {
+		return "", fmt.Errorf("could not read password: %w", err)
+	}
+
+	if len(password) == 0 {
+		return "", errors.New("password is empty")
+	}
+
+	return string(password), nil
+}
+
+// ReadPassword reads the password from the first line of r, e.g. a pipe or
+// a file. Only the line break is removed, so passwords may start or end with
+// spaces.
+func ReadPassword(r io.Reader) (string, error) {
+	password, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("could not read password: %w", err)
+	}
+
+	password = strings.TrimSuffix(password, "\n")
+	password = strings.TrimSuffix(password, "\r")
+	if password == "" {
+		return "", errors.New("password is empty")
+	}
+
+	return password, nil
+}
+
+// readLine reads up to the next line break one byte at a time, so nothing
+// after the line is consumed from in.
+func readLine(in io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := in.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				return string(line), nil
+			}
+			line = append(line, b[0])
+		}
+		if errors.Is(err, io.EOF) && len(line) > 0 {
+			return string(line), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
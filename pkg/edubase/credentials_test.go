@@ -0,0 +1,90 @@
+package edubase
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "line", input: "secret\n", expected: "secret"},
+		{name: "no line break", input: "secret", expected: "secret"},
+		{name: "windows line break", input: "secret\r\n", expected: "secret"},
+		{name: "spaces are kept", input: " sec ret \n", expected: " sec ret "},
+		{name: "only first line", input: "secret\nother\n", expected: "secret"},
+		{name: "empty", input: "", wantErr: true},
+		{name: "empty line", input: "\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadPassword(strings.NewReader(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("read password failed: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPromptEmail(t *testing.T) {
+	in := strings.NewReader("  student@example.com \nrest")
+	var out strings.Builder
+
+	email, err := PromptEmail(in, &out)
+	if err != nil {
+		t.Fatalf("prompt email failed: %v", err)
+	}
+	if email != "student@example.com" {
+		t.Errorf("got %q, want %q", email, "student@example.com")
+	}
+	if out.String() != "Email: " {
+		t.Errorf("got prompt %q, want %q", out.String(), "Email: ")
+	}
+
+	// the rest of the input is not consumed
+	rest := make([]byte, 4)
+	if n, _ := in.Read(rest); string(rest[:n]) != "rest" {
+		t.Errorf("got rest %q, want %q", rest[:n], "rest")
+	}
+
+	if _, err := PromptEmail(strings.NewReader("\n"), &out); err == nil {
+		t.Error("expected error for empty email")
+	}
+}
+
+func TestPromptPasswordNotTerminal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input")
+	if err := os.WriteFile(path, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var out strings.Builder
+	if _, err := PromptPassword(file, &out); !errors.Is(err, ErrNotTerminal) {
+		t.Errorf("got error %v, want %v", err, ErrNotTerminal)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no prompt, got %q", out.String())
+	}
+}
@@ -0,0 +1,444 @@
+// Package edubasetest provides a fake Edubase app for offline end-to-end
+// tests, in the spirit of net/http/httptest.
+//
+// The server mimics the parts of app.edubase.ch the edubase providers rely
+// on: the login modal, the library list and the "#doc/<id>/<page>" reader
+// with its pagination, table of contents and SVG page container. It also
+// serves a stand-in for the Microsoft sign in pages single sign-on accounts
+// are redirected to.
+package edubasetest
+
+import (
+	"bytes"
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Email is the email of the account the server accepts by default.
+	Email = "student@example.com"
+	// Password is the password of the account the server accepts by default.
+	Password = "edubase"
+
+	sessionCookie = "edubase_session"
+)
+
+//go:embed app.html
+var appHTML []byte
+
+//go:embed microsoft.html
+var microsoftHTML []byte
+
+// Book is a book in the fake library.
+type Book struct {
+	Id        int      `json:"id"`
+	Title     string   `json:"title"`
+	Version   string   `json:"version,omitempty"`
+	Authors   []string `json:"authors,omitempty"`
+	Publisher string   `json:"publisher,omitempty"`
+	ISBN      string   `json:"isbn,omitempty"`
+	Pages     int      `json:"pages"`
+	// FrontMatter is the number of pages at the start that the reader
+	// numbers with roman numerals, the pages after them start at 1.
+	FrontMatter int       `json:"frontMatter,omitempty"`
+	TOC         []Chapter `json:"toc,omitempty"`
+	// StuckPages are pages on which the next page button does nothing the
+	// first time it is clicked, like a reader that fails to advance.
+	StuckPages []int `json:"stuckPages,omitempty"`
+	// PageDelay delays the response to every page of the book, like a
+	// reader that got stuck loading.
+	PageDelay time.Duration `json:"-"`
+}
+
+// Chapter is an entry of the table of contents of a book.
+type Chapter struct {
+	Title    string    `json:"title"`
+	Page     int       `json:"page"`
+	Children []Chapter `json:"children,omitempty"`
+}
+
+// DefaultBooks is the library every new server starts with.
+var DefaultBooks = []Book{
+	{
+		Id:        58216,
+		Title:     "Mathematik 1",
+		Version:   "3. Auflage 2023",
+		Authors:   []string{"Anna Muster", "Beat Beispiel"},
+		Publisher: "Lehrmittelverlag Zürich",
+		ISBN:      "978-3-03713-123-4",
+		Pages:     38,
+		TOC: []Chapter{
+			{Title: "1 Zahlen", Page: 1, Children: []Chapter{
+				{Title: "1.1 Natürliche Zahlen", Page: 2},
+				{Title: "1.2 Brüche", Page: 8},
+			}},
+			{Title: "2 Geometrie", Page: 15},
+			{Title: "3 Algebra", Page: 27, Children: []Chapter{
+				{Title: "3.1 Terme", Page: 28},
+				{Title: "3.2 Gleichungen", Page: 33},
+			}},
+		},
+	},
+	{Id: 61532, Title: "Deutsch: Grammatik / Übungen", Pages: 12, FrontMatter: 2},
+}
+
+// PageLabel returns the page number the reader shows for page.
+func (b Book) PageLabel(page int) string {
+	if page > b.FrontMatter {
+		return strconv.Itoa(page - b.FrontMatter)
+	}
+
+	label := ""
+	for _, numeral := range []struct {
+		value int
+		text  string
+	}{{10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"}} {
+		for ; page >= numeral.value; page -= numeral.value {
+			label += numeral.text
+		}
+	}
+	return label
+}
+
+// Server is a fake Edubase app listening on a local loopback address.
+//
+// Email, Password and Books may be changed after NewServer returns; they are
+// read on every request.
+type Server struct {
+	// URL is the base URL of the form http://ipaddr:port with no trailing
+	// slash. Pass it to edubase.WithBaseURL.
+	URL string
+
+	Email    string
+	Password string
+	Books    []Book
+
+	// Locked rejects logins of the account as if it had been locked.
+	Locked bool
+
+	// MicrosoftMFA makes the Microsoft sign in ask to approve the sign in
+	// in an authenticator app after the password.
+	MicrosoftMFA bool
+
+	srv      *httptest.Server
+	mu       sync.Mutex
+	sessions map[string]string
+	// codes are the authorization codes of finished Microsoft sign ins.
+	codes map[string]string
+}
+
+// NewServer starts and returns a new fake Edubase server. The caller should
+// call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Email:    Email,
+		Password: Password,
+		Books:    append([]Book(nil), DefaultBooks...),
+		sessions: map[string]string{},
+		codes:    map[string]string{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleApp)
+	mux.HandleFunc("POST /api/login", s.handleLogin)
+	mux.HandleFunc("POST /api/logout", s.handleLogout)
+	mux.HandleFunc("GET /api/me", s.requireSession(s.handleMe))
+	mux.HandleFunc("GET /api/library", s.requireSession(s.handleLibrary))
+	mux.HandleFunc("GET /api/books/{id}", s.requireSession(s.handleBook))
+	mux.HandleFunc("GET /api/books/{id}/pages/{page}", s.requireSession(s.handlePage))
+	mux.HandleFunc("GET /api/books/{id}/cover.png", s.requireSession(s.handleCover))
+	mux.HandleFunc("GET /auth/microsoft", s.handleMicrosoft)
+	mux.HandleFunc("POST /auth/microsoft/user", s.handleMicrosoftUser)
+	mux.HandleFunc("POST /auth/microsoft/password", s.handleMicrosoftPassword)
+	mux.HandleFunc("POST /auth/microsoft/callback", s.handleMicrosoftCallback)
+
+	s.srv = httptest.NewServer(mux)
+	s.URL = s.srv.URL
+
+	return s
+}
+
+// Close shuts down the server and blocks until all outstanding requests on
+// it have completed.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// ExpireSessions logs out every client, as if their sessions had expired.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	s.sessions = map[string]string{}
+	s.mu.Unlock()
+}
+
+// Book returns the book with the given id.
+func (s *Server) Book(id int) (Book, bool) {
+	for _, book := range s.Books {
+		if book.Id == id {
+			return book, true
+		}
+	}
+	return Book{}, false
+}
+
+func (s *Server) handleApp(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(appHTML)
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Login    string `json:"login"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	if body.Login != s.Email || body.Password != s.Password {
+		writeError(w, http.StatusUnauthorized, "Invalid email or password.")
+		return
+	}
+
+	if s.Locked {
+		writeError(w, http.StatusForbidden, "Ihr Konto wurde gesperrt. Bitte wenden Sie sich an den Support.")
+		return
+	}
+
+	s.startSession(w, body.Login)
+	writeJSON(w, http.StatusOK, map[string]string{"email": body.Login})
+}
+
+// startSession logs the client in as email by setting a session cookie.
+func (s *Server) startSession(w http.ResponseWriter, email string) {
+	token := newToken()
+	s.mu.Lock()
+	s.sessions[token] = email
+	s.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+	})
+}
+
+func (s *Server) handleMicrosoft(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(microsoftHTML)
+}
+
+func (s *Server) handleMicrosoftUser(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Login string `json:"login"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	if body.Login != s.Email {
+		writeError(w, http.StatusNotFound, "This username may be incorrect. Make sure you typed it correctly.")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{})
+}
+
+func (s *Server) handleMicrosoftPassword(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Login    string `json:"login"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+
+	if body.Login != s.Email || body.Password != s.Password {
+		writeError(w, http.StatusUnauthorized, "Your account or password is incorrect.")
+		return
+	}
+
+	if s.Locked {
+		writeError(w, http.StatusForbidden, "Your account has been locked. Contact your support person to unlock it.")
+		return
+	}
+
+	if s.MicrosoftMFA {
+		writeJSON(w, http.StatusOK, map[string]bool{"mfa": true})
+		return
+	}
+
+	code := newToken()
+	s.mu.Lock()
+	s.codes[code] = body.Login
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]string{"code": code})
+}
+
+func (s *Server) handleMicrosoftCallback(w http.ResponseWriter, r *http.Request) {
+	code := r.FormValue("code")
+
+	s.mu.Lock()
+	email, ok := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid code")
+		return
+	}
+
+	s.startSession(w, email)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		s.mu.Lock()
+		delete(s.sessions, cookie.Value)
+		s.mu.Unlock()
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Path: "/", MaxAge: -1})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"email": s.Email})
+}
+
+func (s *Server) handleLibrary(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Books)
+}
+
+func (s *Server) handleBook(w http.ResponseWriter, r *http.Request) {
+	book, ok := s.bookFromPath(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "book not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, book)
+}
+
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	book, ok := s.bookFromPath(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "book not found")
+		return
+	}
+
+	page, err := strconv.Atoi(r.PathValue("page"))
+	if err != nil || page < 1 || page > book.Pages {
+		writeError(w, http.StatusNotFound, "page not found")
+		return
+	}
+
+	if book.PageDelay > 0 {
+		select {
+		case <-time.After(book.PageDelay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	fmt.Fprint(w, pageSVG(book, page))
+}
+
+func (s *Server) handleCover(w http.ResponseWriter, r *http.Request) {
+	if _, ok := s.bookFromPath(r); !ok {
+		writeError(w, http.StatusNotFound, "book not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(coverPNG)
+}
+
+func (s *Server) bookFromPath(r *http.Request) (Book, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return Book{}, false
+	}
+	return s.Book(id)
+}
+
+func (s *Server) requireSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, "not logged in")
+			return
+		}
+
+		s.mu.Lock()
+		_, ok := s.sessions[cookie.Value]
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "not logged in")
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// coverPNG is a small image every page references, so clients have to
+// resolve external resources of the page SVG.
+var coverPNG = func() []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.RGBA{R: 0x0b, G: 0x53, B: 0x94, A: 0xff}}, image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		panic(fmt.Sprintf("edubasetest: could not encode cover: %v", err))
+	}
+	return buf.Bytes()
+}()
+
+// pageSVG renders a simple A4 page that shows the book title and page number.
+func pageSVG(book Book, page int) string {
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 595 842" width="595" height="842">
+<rect width="595" height="842" fill="#ffffff"/>
+<image href="/api/books/%d/cover.png" x="495" y="60" width="40" height="40"/>
+<text x="60" y="100" font-family="Helvetica, Arial, sans-serif" font-size="28">%s</text>
+<text x="60" y="150" font-family="Helvetica, Arial, sans-serif" font-size="16">Page %d of %d</text>
+<text x="297" y="800" font-family="Helvetica, Arial, sans-serif" font-size="12" text-anchor="middle">%d</text>
+</svg>`, book.Id, html.EscapeString(book.Title), page, book.Pages, page)
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("edubasetest: could not generate session token: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
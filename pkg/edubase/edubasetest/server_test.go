@@ -0,0 +1,275 @@
+package edubasetest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"strings"
+	"testing"
+)
+
+func newTestClient(t *testing.T) *http.Client {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("could not create cookie jar: %v", err)
+	}
+	return &http.Client{Jar: jar}
+}
+
+func login(t *testing.T, client *http.Client, s *Server, email, password string) *http.Response {
+	body := strings.NewReader(`{"login":"` + email + `","password":"` + password + `"}`)
+	res, err := client.Post(s.URL+"/api/login", "application/json", body)
+	if err != nil {
+		t.Fatalf("login request failed: %v", err)
+	}
+	res.Body.Close()
+	return res
+}
+
+func TestServeApp(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	res, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatalf("could not get app: %v", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("could not read app: %v", err)
+	}
+
+	for _, want := range []string{"data-open=\"loginModal\"", "name=\"login\"", "id=\"libraryItems\"", "id=\"pagination\"", "lu-page-svg-container"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("app does not contain %q", want)
+		}
+	}
+}
+
+func TestLogin(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	client := newTestClient(t)
+
+	if res := login(t, client, s, Email, "wrong"); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("login with wrong password: got status %d, want %d", res.StatusCode, http.StatusUnauthorized)
+	}
+
+	res, err := client.Get(s.URL + "/api/library")
+	if err != nil {
+		t.Fatalf("library request failed: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("library without session: got status %d, want %d", res.StatusCode, http.StatusUnauthorized)
+	}
+
+	if res := login(t, client, s, Email, Password); res.StatusCode != http.StatusOK {
+		t.Fatalf("login: got status %d, want %d", res.StatusCode, http.StatusOK)
+	}
+
+	res, err = client.Get(s.URL + "/api/library")
+	if err != nil {
+		t.Fatalf("library request failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	var books []Book
+	if err := json.NewDecoder(res.Body).Decode(&books); err != nil {
+		t.Fatalf("could not decode library: %v", err)
+	}
+	if len(books) != len(DefaultBooks) {
+		t.Errorf("got %d books, want %d", len(books), len(DefaultBooks))
+	}
+}
+
+func TestPage(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	client := newTestClient(t)
+	login(t, client, s, Email, Password)
+
+	book := DefaultBooks[1]
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/api/books/61532/pages/1", http.StatusOK},
+		{"/api/books/61532/pages/12", http.StatusOK},
+		{"/api/books/61532/pages/13", http.StatusNotFound},
+		{"/api/books/61532/pages/0", http.StatusNotFound},
+		{"/api/books/1/pages/1", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		res, err := client.Get(s.URL + tt.path)
+		if err != nil {
+			t.Fatalf("page request failed: %v", err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+
+		if res.StatusCode != tt.status {
+			t.Errorf("GET %s: got status %d, want %d", tt.path, res.StatusCode, tt.status)
+			continue
+		}
+
+		if tt.status == http.StatusOK && !strings.Contains(string(body), "Grammatik / Übungen") {
+			t.Errorf("GET %s: page does not contain title %q", tt.path, book.Title)
+		}
+	}
+}
+
+func TestBookTOC(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	client := newTestClient(t)
+	login(t, client, s, Email, Password)
+
+	res, err := client.Get(s.URL + "/api/books/58216")
+	if err != nil {
+		t.Fatalf("book request failed: %v", err)
+	}
+	defer res.Body.Close()
+
+	var book Book
+	if err := json.NewDecoder(res.Body).Decode(&book); err != nil {
+		t.Fatalf("could not decode book: %v", err)
+	}
+
+	if len(book.TOC) != len(DefaultBooks[0].TOC) {
+		t.Fatalf("got %d chapters, want %d", len(book.TOC), len(DefaultBooks[0].TOC))
+	}
+	if got := book.TOC[0].Children[1]; got.Title != "1.2 Brüche" || got.Page != 8 {
+		t.Errorf("got chapter %+v, want 1.2 Brüche on page 8", got)
+	}
+}
+
+func TestPageLabel(t *testing.T) {
+	book := Book{Pages: 20, FrontMatter: 14}
+
+	tests := map[int]string{1: "i", 4: "iv", 9: "ix", 14: "xiv", 15: "1", 20: "6"}
+	for page, want := range tests {
+		if got := book.PageLabel(page); got != want {
+			t.Errorf("PageLabel(%d) = %q, want %q", page, got, want)
+		}
+	}
+}
+
+func TestExpireSessions(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	client := newTestClient(t)
+	login(t, client, s, Email, Password)
+
+	s.ExpireSessions()
+
+	res, err := client.Get(s.URL + "/api/me")
+	if err != nil {
+		t.Fatalf("me request failed: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("me after expiry: got status %d, want %d", res.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func postJSON(t *testing.T, client *http.Client, url, body string) *http.Response {
+	res, err := client.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("request to %s failed: %v", url, err)
+	}
+	return res
+}
+
+func TestMicrosoftLogin(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	client := newTestClient(t)
+
+	res := postJSON(t, client, s.URL+"/auth/microsoft/user", `{"login":"other@example.com"}`)
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown user: got status %d, want %d", res.StatusCode, http.StatusNotFound)
+	}
+
+	res = postJSON(t, client, s.URL+"/auth/microsoft/password", `{"login":"`+Email+`","password":"wrong"}`)
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong password: got status %d, want %d", res.StatusCode, http.StatusUnauthorized)
+	}
+
+	res = postJSON(t, client, s.URL+"/auth/microsoft/password", `{"login":"`+Email+`","password":"`+Password+`"}`)
+	var body struct {
+		Code string `json:"code"`
+	}
+	err := json.NewDecoder(res.Body).Decode(&body)
+	res.Body.Close()
+	if err != nil || body.Code == "" {
+		t.Fatalf("could not get code: %v", err)
+	}
+
+	res, err = client.PostForm(s.URL+"/auth/microsoft/callback", map[string][]string{"code": {body.Code}})
+	if err != nil {
+		t.Fatalf("callback request failed: %v", err)
+	}
+	res.Body.Close()
+
+	res, err = client.Get(s.URL + "/api/me")
+	if err != nil {
+		t.Fatalf("me request failed: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("me after microsoft login: got status %d, want %d", res.StatusCode, http.StatusOK)
+	}
+}
+
+func TestMicrosoftLoginMFA(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.MicrosoftMFA = true
+
+	client := newTestClient(t)
+
+	res := postJSON(t, client, s.URL+"/auth/microsoft/password", `{"login":"`+Email+`","password":"`+Password+`"}`)
+	defer res.Body.Close()
+
+	var body struct {
+		MFA  bool   `json:"mfa"`
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	if !body.MFA || body.Code != "" {
+		t.Errorf("got %+v, want mfa without code", body)
+	}
+}
+
+func TestLoginLocked(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Locked = true
+
+	client := newTestClient(t)
+
+	if res := login(t, client, s, Email, Password); res.StatusCode != http.StatusForbidden {
+		t.Errorf("login to locked account: got status %d, want %d", res.StatusCode, http.StatusForbidden)
+	}
+
+	res := postJSON(t, client, s.URL+"/auth/microsoft/password", `{"login":"`+Email+`","password":"`+Password+`"}`)
+	res.Body.Close()
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("microsoft login to locked account: got status %d, want %d", res.StatusCode, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,71 @@
+package edubase
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Errors of a failed login. They are wrapped together with the details, so
+// use errors.Is to check for them.
+var (
+	// ErrInvalidCredentials means the email or password was rejected.
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrAccountLocked means the account was locked or disabled, e.g. after
+	// too many failed logins.
+	ErrAccountLocked = errors.New("account is locked")
+	// ErrLoginPageChanged means an element the login relies on is missing,
+	// most likely because the login page was redesigned.
+	ErrLoginPageChanged = errors.New("login page has changed")
+	// ErrTimeout means the login page did not respond within the timeout.
+	ErrTimeout = errors.New("login timed out")
+)
+
+// lockedPhrases are parts of the error messages of Edubase and Microsoft
+// that say the account is locked rather than the password is wrong.
+var lockedPhrases = []string{
+	"locked",
+	"blocked",
+	"disabled",
+	"too many",
+	"gesperrt",
+	"blockiert",
+	"deaktiviert",
+	"zu viele",
+}
+
+// loginMessageError returns the error for an error message shown by a login
+// form.
+func loginMessageError(message string) error {
+	lower := strings.ToLower(message)
+	for _, phrase := range lockedPhrases {
+		if strings.Contains(lower, phrase) {
+			return fmt.Errorf("%w: %s", ErrAccountLocked, message)
+		}
+	}
+
+	if message == "" {
+		return ErrInvalidCredentials
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidCredentials, message)
+}
+
+// missingElementError returns ErrLoginPageChanged for an element the login
+// waited for in vain, and err itself for other errors, e.g. a canceled ctx.
+func missingElementError(element string, err error) error {
+	if errors.Is(err, playwright.ErrTimeout) {
+		return fmt.Errorf("%w: could not find %s", ErrLoginPageChanged, element)
+	}
+	return fmt.Errorf("could not find %s: %w", element, err)
+}
+
+// timeoutError returns ErrTimeout for a page that did not load in time, and
+// err itself for other errors.
+func timeoutError(action string, err error) error {
+	if errors.Is(err, playwright.ErrTimeout) {
+		return fmt.Errorf("%w: could not %s: %v", ErrTimeout, action, err)
+	}
+	return fmt.Errorf("could not %s: %w", action, err)
+}
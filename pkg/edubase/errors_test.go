@@ -0,0 +1,54 @@
+package edubase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+func TestLoginMessageError(t *testing.T) {
+	tests := []struct {
+		message  string
+		expected error
+	}{
+		{message: "Invalid email or password.", expected: ErrInvalidCredentials},
+		{message: "E-Mail oder Passwort ist falsch.", expected: ErrInvalidCredentials},
+		{message: "", expected: ErrInvalidCredentials},
+		{message: "Ihr Konto wurde gesperrt.", expected: ErrAccountLocked},
+		{message: "Your account has been locked.", expected: ErrAccountLocked},
+		{message: "Too many failed attempts, try again later.", expected: ErrAccountLocked},
+	}
+
+	for _, tt := range tests {
+		err := loginMessageError(tt.message)
+		if !errors.Is(err, tt.expected) {
+			t.Errorf("message %q: got error %v, want %v", tt.message, err, tt.expected)
+		}
+	}
+}
+
+func TestMissingElementError(t *testing.T) {
+	timeout := fmt.Errorf("%w: %w: waiting for locator", playwright.ErrPlaywright, playwright.ErrTimeout)
+	if err := missingElementError("login button", timeout); !errors.Is(err, ErrLoginPageChanged) {
+		t.Errorf("got error %v, want %v", err, ErrLoginPageChanged)
+	}
+
+	err := missingElementError("login button", context.Canceled)
+	if errors.Is(err, ErrLoginPageChanged) || !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	timeout := fmt.Errorf("%w: %w: navigation", playwright.ErrPlaywright, playwright.ErrTimeout)
+	if err := timeoutError("go to base page", timeout); !errors.Is(err, ErrTimeout) {
+		t.Errorf("got error %v, want %v", err, ErrTimeout)
+	}
+
+	if err := timeoutError("go to base page", context.Canceled); errors.Is(err, ErrTimeout) {
+		t.Errorf("got error %v, want no %v", err, ErrTimeout)
+	}
+}
@@ -1,8 +1,10 @@
 package edubase
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/playwright-community/playwright-go"
@@ -14,29 +16,47 @@ type LibraryProvider struct {
 	Books              []Book
 	timeout            time.Duration
 	stabilizationDelay time.Duration
+	selectors          Selectors
 }
 
-func NewLibraryProvider(page playwright.Page) *LibraryProvider {
+func NewLibraryProvider(page playwright.Page, opts ...Option) *LibraryProvider {
+	c := newConfig(opts)
+
 	return &LibraryProvider{
 		page:               page,
-		baseURL:            "https://app.edubase.ch",
+		baseURL:            c.BaseURL,
 		Books:              []Book{},
-		timeout:            15 * time.Second,
-		stabilizationDelay: 2 * time.Second,
+		timeout:            c.Timeout,
+		stabilizationDelay: c.StabilizationDelay,
+		selectors:          c.Selectors,
 	}
 }
 
 type Book struct {
-	Id    int
-	Title string
+	Id    int    `json:"id"`
+	Title string `json:"title"`
+	// Version is the edition shown in the library, empty if there is none.
+	Version string `json:"version"`
+	// Authors, Publisher and ISBN are empty if the library does not show
+	// them for the book.
+	Authors   []string `json:"authors,omitempty"`
+	Publisher string   `json:"publisher,omitempty"`
+	ISBN      string   `json:"isbn,omitempty"`
 }
 
 func (l *LibraryProvider) GetBooks() ([]Book, error) {
+	return l.GetBooksContext(context.Background())
+}
+
+// GetBooksContext is like GetBooks but returns early when ctx is done.
+func (l *LibraryProvider) GetBooksContext(ctx context.Context) ([]Book, error) {
 	// wait for at least one library item to be visible in the DOM
-	itemLocator := l.page.Locator("#libraryItems > li:not(:first-child)")
-	err := itemLocator.First().WaitFor(playwright.LocatorWaitForOptions{
-		State:   playwright.WaitForSelectorStateVisible,
-		Timeout: playwright.Float(float64(l.timeout.Milliseconds())),
+	itemLocator := l.page.Locator(l.selectors.Library.Item)
+	err := await(ctx, func() error {
+		return itemLocator.First().WaitFor(playwright.LocatorWaitForOptions{
+			State:   playwright.WaitForSelectorStateVisible,
+			Timeout: playwright.Float(float64(l.timeout.Milliseconds())),
+		})
 	})
 	if err != nil {
 		return []Book{}, fmt.Errorf("timed out waiting for library items to appear: %w", err)
@@ -46,18 +66,26 @@ func (l *LibraryProvider) GetBooks() ([]Book, error) {
 	// NetworkIdle may not resolve if the app uses persistent connections (e.g.
 	// WebSockets, long-polling). A timeout here is acceptable because the
 	// stabilization delay below still gives remaining items time to render.
-	if err := l.page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
-		State:   playwright.LoadStateNetworkidle,
-		Timeout: playwright.Float(float64(l.timeout.Milliseconds())),
-	}); err != nil {
-		// non-fatal: proceed with whatever has loaded
+	if err := await(ctx, func() error {
+		return l.page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+			State:   playwright.LoadStateNetworkidle,
+			Timeout: playwright.Float(float64(l.timeout.Milliseconds())),
+		})
+	}); err != nil && ctx.Err() != nil {
+		return []Book{}, fmt.Errorf("could not wait for library to load: %w", err)
 	}
+	// other errors are non-fatal: proceed with whatever has loaded
 
 	// allow final DOM mutations after last API response
-	time.Sleep(l.stabilizationDelay)
+	if err := Sleep(ctx, l.stabilizationDelay); err != nil {
+		return []Book{}, fmt.Errorf("could not wait for library to load: %w", err)
+	}
 
-	libraryItems, err := itemLocator.All()
-	if err != nil {
+	var libraryItems []playwright.Locator
+	if err := await(ctx, func() (err error) {
+		libraryItems, err = itemLocator.All()
+		return err
+	}); err != nil {
 		return []Book{}, err
 	}
 
@@ -65,12 +93,16 @@ func (l *LibraryProvider) GetBooks() ([]Book, error) {
 	l.Books = nil
 
 	for _, libraryItem := range libraryItems {
-		bookId, err := libraryItem.GetAttribute("data-last-available-version")
+		if err := ctx.Err(); err != nil {
+			return []Book{}, fmt.Errorf("could not read library items: %w", err)
+		}
+
+		bookId, err := libraryItem.GetAttribute(l.selectors.Library.ItemIdAttribute)
 		if err != nil {
 			continue
 		}
 
-		title, err := libraryItem.Locator(".lu-library-item-title").First().InnerText()
+		title, err := libraryItem.Locator(l.selectors.Library.ItemTitle).First().InnerText()
 		if err != nil {
 			continue
 		}
@@ -80,11 +112,58 @@ func (l *LibraryProvider) GetBooks() ([]Book, error) {
 			continue
 		}
 
+		// not every book shows an edition or the other details, so do not
+		// wait for them
+		authors := optionalTexts(libraryItem.Locator(l.selectors.Library.ItemAuthor))
+
 		l.Books = append(l.Books, Book{
-			Id:    bookIdInt,
-			Title: title,
+			Id:        bookIdInt,
+			Title:     title,
+			Version:   optionalText(libraryItem.Locator(l.selectors.Library.ItemVersion)),
+			Authors:   authors,
+			Publisher: optionalText(libraryItem.Locator(l.selectors.Library.ItemPublisher)),
+			ISBN:      parseISBN(optionalText(libraryItem.Locator(l.selectors.Library.ItemISBN))),
 		})
 	}
 
 	return l.Books, nil
 }
+
+// optionalText returns the text of the first element of locator, or "" if
+// there is none.
+func optionalText(locator playwright.Locator) string {
+	if count, err := locator.Count(); err != nil || count == 0 {
+		return ""
+	}
+	text, err := locator.First().InnerText()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(text)
+}
+
+// optionalTexts returns the non-empty texts of all elements of locator.
+func optionalTexts(locator playwright.Locator) []string {
+	elements, err := locator.All()
+	if err != nil {
+		return nil
+	}
+
+	var texts []string
+	for _, element := range elements {
+		if text, err := element.InnerText(); err == nil && strings.TrimSpace(text) != "" {
+			texts = append(texts, strings.TrimSpace(text))
+		}
+	}
+	return texts
+}
+
+// parseISBN strips the "ISBN" label the library shows in front of the
+// number.
+func parseISBN(text string) string {
+	text = strings.TrimSpace(text)
+	if len(text) >= 4 && strings.EqualFold(text[:4], "ISBN") {
+		text = text[4:]
+	}
+	return strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(text), ":"))
+}
@@ -1,6 +1,7 @@
 package edubase
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -39,3 +40,44 @@ func TestGetBooks(t *testing.T) {
 		t.Errorf("book has no title")
 	}
 }
+
+func TestGetBooksOffline(t *testing.T) {
+	server, page := setupTestServerWithLogin(t)
+
+	libraryProvider := NewLibraryProvider(page, WithBaseURL(server.URL))
+
+	books, err := libraryProvider.GetBooks()
+	if err != nil {
+		t.Fatalf("get books failed: %v", err)
+	}
+
+	if len(books) != len(server.Books) {
+		t.Fatalf("got %d books, want %d", len(books), len(server.Books))
+	}
+
+	for i, book := range books {
+		want := server.Books[i]
+		if book.Id != want.Id || book.Title != want.Title || book.Version != want.Version ||
+			!slices.Equal(book.Authors, want.Authors) || book.Publisher != want.Publisher || book.ISBN != want.ISBN {
+			t.Errorf("book %d: got %+v, want %+v", i, book, want)
+		}
+	}
+}
+
+func TestParseISBN(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"ISBN 978-3-03713-123-4", "978-3-03713-123-4"},
+		{"isbn: 978-3-03713-123-4 ", "978-3-03713-123-4"},
+		{"978-3-03713-123-4", "978-3-03713-123-4"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := parseISBN(tt.text); got != tt.want {
+			t.Errorf("parseISBN(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
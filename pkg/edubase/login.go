@@ -1,12 +1,11 @@
 package edubase
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"time"
-
-	"bufio"
-	"os"
 	"strings"
+	"time"
 
 	"github.com/playwright-community/playwright-go"
 )
@@ -14,171 +13,294 @@ import (
 type LoginProvider struct {
 	page              playwright.Page
 	baseURL           string
+	timeout           time.Duration
 	passwordFillDelay time.Duration
 	verifyLoginDelay  time.Duration
+	loginMethod       LoginMethod
+	selectors         Selectors
 }
 
-func NewLoginProvider(page playwright.Page) *LoginProvider {
+func NewLoginProvider(page playwright.Page, opts ...Option) *LoginProvider {
+	c := newConfig(opts)
+
 	return &LoginProvider{
 		page:              page,
-		baseURL:           "https://app.edubase.ch",
-		passwordFillDelay: 500 * time.Millisecond,
-		verifyLoginDelay:  500 * time.Millisecond,
+		baseURL:           c.BaseURL,
+		timeout:           c.Timeout,
+		passwordFillDelay: c.PasswordFillDelay,
+		verifyLoginDelay:  c.VerifyLoginDelay,
+		loginMethod:       c.LoginMethod,
+		selectors:         c.Selectors,
 	}
 }
 
+// LoginMethod is the way an account logs in to Edubase.
+type LoginMethod string
+
+const (
+	// LoginMethodEdubase logs in with the Edubase login form.
+	LoginMethodEdubase LoginMethod = "edubase"
+	// LoginMethodMicrosoft logs in with Microsoft single sign-on, as used by
+	// many school accounts.
+	LoginMethodMicrosoft LoginMethod = "microsoft"
+)
+
 type Credentials struct {
 	Email    string
 	Password string
 }
 
-func GetCredentials() (Credentials, error) {
-	credentials := Credentials{}
-
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Print("Email: ")
-	email, err := reader.ReadString('\n')
-	if err != nil {
-		return Credentials{}, err
-	}
-	credentials.Email = strings.TrimSpace(email)
-
-	fmt.Print("Password: ")
-	password, err := reader.ReadString('\n')
-	if err != nil {
-		return Credentials{}, err
-	}
-	credentials.Password = strings.TrimSpace(password)
-
-	return credentials, nil
+func (l *LoginProvider) Login(credentials Credentials, manualLogin bool) error {
+	return l.LoginContext(context.Background(), credentials, manualLogin)
 }
 
-func (l *LoginProvider) Login(credentials Credentials, manualLogin bool) error {
-	if err := l.setupLoginPage(); err != nil {
+// LoginContext is like Login but returns early when ctx is done. A manual
+// login waits for the user until ctx is done.
+func (l *LoginProvider) LoginContext(ctx context.Context, credentials Credentials, manualLogin bool) error {
+	if err := l.setupLoginPage(ctx); err != nil {
 		return err
 	}
 
 	if manualLogin {
-		return l.handleManualLogin()
+		return l.handleManualLogin(ctx)
 	}
 
-	return l.handleAutomaticLogin(credentials)
+	if l.loginMethod == LoginMethodMicrosoft {
+		return l.handleMicrosoftLogin(ctx, credentials)
+	}
+
+	return l.handleAutomaticLogin(ctx, credentials)
 }
 
-func (l *LoginProvider) setupLoginPage() error {
+func (l *LoginProvider) setupLoginPage(ctx context.Context) error {
 	// clear all cookies and local storage
-	if err := l.page.Context().ClearCookies(); err != nil {
-		return fmt.Errorf("could not clear cookies: %v", err)
+	if err := await(ctx, func() error {
+		return l.page.Context().ClearCookies()
+	}); err != nil {
+		return fmt.Errorf("could not clear cookies: %w", err)
 	}
 
 	// go to login page
-	if _, err := l.page.Goto(l.baseURL); err != nil {
-		return fmt.Errorf("could not go to base page: %v", err)
+	if err := await(ctx, func() error {
+		_, err := l.page.Goto(l.baseURL)
+		return err
+	}); err != nil {
+		return timeoutError("go to base page", err)
 	}
 
 	// wait for page to load
-	if err := l.page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
-		State:   playwright.LoadStateNetworkidle,
-		Timeout: playwright.Float(15000),
+	if err := await(ctx, func() error {
+		return l.page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+			State:   playwright.LoadStateNetworkidle,
+			Timeout: playwright.Float(float64(l.timeout.Milliseconds())),
+		})
 	}); err != nil {
-		return fmt.Errorf("could not wait for navigation: %v", err)
+		return timeoutError("wait for navigation", err)
 	}
 
 	// press login button
-	if err := l.page.Locator("button[data-open='loginModal']").Click(); err != nil {
-		return fmt.Errorf("could not click login button: %v", err)
+	loginButton := l.page.Locator(l.selectors.Login.OpenButton)
+	if err := l.waitVisible(ctx, loginButton); err != nil {
+		return missingElementError("login button", err)
+	}
+	if err := await(ctx, func() error {
+		return loginButton.Click()
+	}); err != nil {
+		return fmt.Errorf("could not click login button: %w", err)
 	}
 
 	return nil
 }
 
-func (l *LoginProvider) handleManualLogin() error {
+func (l *LoginProvider) handleManualLogin(ctx context.Context) error {
 	// wait for user to complete login
-	if err := l.page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
-		State: playwright.LoadStateNetworkidle,
+	if err := await(ctx, func() error {
+		return l.page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+			State: playwright.LoadStateNetworkidle,
+		})
 	}); err != nil {
-		return fmt.Errorf("could not wait for navigation: %v", err)
+		return fmt.Errorf("could not wait for navigation: %w", err)
 	}
 
-	return l.waitForLoginSuccess()
+	return l.waitForLoginSuccess(ctx)
 }
 
-func (l *LoginProvider) handleAutomaticLogin(credentials Credentials) error {
-	if err := l.fillLoginForm(credentials); err != nil {
+func (l *LoginProvider) handleAutomaticLogin(ctx context.Context, credentials Credentials) error {
+	if err := l.fillLoginForm(ctx, credentials); err != nil {
 		return err
 	}
 
-	if err := l.submitLoginForm(); err != nil {
+	if err := l.submitLoginForm(ctx); err != nil {
 		return err
 	}
 
 	// wait for login to complete
-	time.Sleep(l.verifyLoginDelay)
+	if err := Sleep(ctx, l.verifyLoginDelay); err != nil {
+		return fmt.Errorf("could not verify login: %w", err)
+	}
 
-	return l.verifyLoginSuccess()
+	return l.verifyLoginSuccess(ctx)
 }
 
-func (l *LoginProvider) fillLoginForm(credentials Credentials) error {
+func (l *LoginProvider) fillLoginForm(ctx context.Context, credentials Credentials) error {
 	// get login input
-	loginInput := l.page.Locator("input[name='login']")
-	loginInput.Fill(credentials.Email)
-
-	// check if input is visible
-	isVisible, err := loginInput.IsVisible()
-	if err != nil || !isVisible {
-		return fmt.Errorf("could not fill login input: %v", err)
+	loginInput := l.page.Locator(l.selectors.Login.EmailInput)
+	if err := l.waitVisible(ctx, loginInput); err != nil {
+		return missingElementError("login input", err)
+	}
+	if err := await(ctx, func() error {
+		return loginInput.Fill(credentials.Email)
+	}); err != nil {
+		return fmt.Errorf("could not fill login input: %w", err)
 	}
 
 	// wait for password fill delay
-	time.Sleep(l.passwordFillDelay)
+	if err := Sleep(ctx, l.passwordFillDelay); err != nil {
+		return fmt.Errorf("could not fill password input: %w", err)
+	}
 
 	// get password input
-	passwordInput := l.page.Locator("input[name='password']")
-	passwordInput.Fill(credentials.Password)
+	passwordInput := l.page.Locator(l.selectors.Login.PasswordInput)
+	if err := l.waitVisible(ctx, passwordInput); err != nil {
+		return missingElementError("password input", err)
+	}
+	if err := await(ctx, func() error {
+		return passwordInput.Fill(credentials.Password)
+	}); err != nil {
+		return fmt.Errorf("could not fill password input: %w", err)
+	}
 
 	return nil
 }
 
-func (l *LoginProvider) submitLoginForm() error {
+func (l *LoginProvider) submitLoginForm(ctx context.Context) error {
 	// submit form
-	if err := l.page.Locator("button[type='submit']").Click(); err != nil {
-		return fmt.Errorf("could not submit login form: %v", err)
+	submitButton := l.page.Locator(l.selectors.Login.SubmitButton)
+	if err := l.waitVisible(ctx, submitButton); err != nil {
+		return missingElementError("login submit button", err)
+	}
+	if err := await(ctx, func() error {
+		return submitButton.Click()
+	}); err != nil {
+		return fmt.Errorf("could not submit login form: %w", err)
 	}
 
 	// wait for login to complete
-	if err := l.page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
-		State: playwright.LoadStateNetworkidle,
+	if err := await(ctx, func() error {
+		return l.page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+			State: playwright.LoadStateNetworkidle,
+		})
 	}); err != nil {
-		return fmt.Errorf("could not wait for navigation: %v", err)
+		return timeoutError("wait for navigation", err)
 	}
 
 	return nil
 }
 
-func (l *LoginProvider) waitForLoginSuccess() error {
+func (l *LoginProvider) waitForLoginSuccess(ctx context.Context) error {
 	//check in a while loop if login was successful (check for account button)
 	for {
 		accountButton := l.getAccountButton()
-		isVisible, err := accountButton.IsVisible()
+		var isVisible bool
+		err := await(ctx, func() (err error) {
+			isVisible, err = accountButton.IsVisible()
+			return err
+		})
+		if ctx.Err() != nil {
+			return fmt.Errorf("could not wait for manual login: %w", ctx.Err())
+		}
 		if accountButton != nil && err == nil && isVisible {
 			break
 		}
-		time.Sleep(1 * time.Second)
+		if err := Sleep(ctx, 1*time.Second); err != nil {
+			return fmt.Errorf("could not wait for manual login: %w", err)
+		}
 	}
 	return nil
 }
 
-func (l *LoginProvider) verifyLoginSuccess() error {
-	// check if login was successful (check for account button)
+// verifyLoginSuccess waits for the account button or the error message of
+// the login modal, and tells apart why the login failed.
+func (l *LoginProvider) verifyLoginSuccess(ctx context.Context) error {
 	accountButton := l.getAccountButton()
-	isVisible, err := accountButton.IsVisible()
-	if accountButton == nil || err != nil || !isVisible {
-		return fmt.Errorf("login failed (could not find account button)")
+	loginError := l.page.Locator(l.selectors.Login.Error)
+
+	found, err := l.waitForAny(ctx, accountButton, loginError)
+	if err == nil {
+		if found == loginError {
+			return loginMessageError(l.textOf(ctx, loginError))
+		}
+		return nil
 	}
-	return nil
+	if !errors.Is(err, playwright.ErrTimeout) {
+		return fmt.Errorf("could not verify login: %w", err)
+	}
+
+	// a form that is still open got no answer, a closed one without account
+	// button means the navbar has changed
+	var formVisible bool
+	if err := await(ctx, func() (err error) {
+		formVisible, err = l.page.Locator(l.selectors.Login.EmailInput).IsVisible()
+		return err
+	}); err != nil {
+		return fmt.Errorf("could not verify login: %w", err)
+	}
+	if formVisible {
+		return fmt.Errorf("%w: no answer to the login form", ErrTimeout)
+	}
+
+	return fmt.Errorf("%w: could not find account button", ErrLoginPageChanged)
+}
+
+// waitVisible waits until locator is visible, at most for the timeout.
+func (l *LoginProvider) waitVisible(ctx context.Context, locator playwright.Locator) error {
+	return await(ctx, func() error {
+		return locator.WaitFor(playwright.LocatorWaitForOptions{
+			State:   playwright.WaitForSelectorStateVisible,
+			Timeout: playwright.Float(float64(l.timeout.Milliseconds())),
+		})
+	})
+}
+
+// waitForAny waits until one of locators is visible and returns it.
+func (l *LoginProvider) waitForAny(ctx context.Context, locators ...playwright.Locator) (playwright.Locator, error) {
+	combined := locators[0]
+	for _, locator := range locators[1:] {
+		combined = combined.Or(locator)
+	}
+
+	if err := l.waitVisible(ctx, combined.First()); err != nil {
+		return nil, err
+	}
+
+	for _, locator := range locators {
+		var isVisible bool
+		if err := await(ctx, func() (err error) {
+			isVisible, err = locator.First().IsVisible()
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		if isVisible {
+			return locator, nil
+		}
+	}
+
+	// the page moved on between the checks
+	return nil, errors.New("page changed while waiting")
+}
+
+// textOf returns the text of locator for error messages, or an empty string
+// if it cannot be read.
+func (l *LoginProvider) textOf(ctx context.Context, locator playwright.Locator) string {
+	var text string
+	await(ctx, func() (err error) {
+		text, err = locator.First().TextContent()
+		return err
+	})
+	return strings.TrimSpace(text)
 }
 
 func (l *LoginProvider) getAccountButton() playwright.Locator {
-	return l.page.Locator("#main-navbar > nav > ul.header-controls-nav.d-flex.mr-4 > li:nth-child(5) > div > div.btn.lookup-dropdown.lookup-dropdown_no-space-between.border-0.w-auto.pl-0 > i.svg-icon-user.users-profile-icon.svg-icon-primary__border.mr-2").First()
+	return l.page.Locator(l.selectors.Login.AccountButton).First()
 }
@@ -1,8 +1,11 @@
 package edubase
 
 import (
+	"errors"
 	"os"
 	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase/edubasetest"
 )
 
 func TestLogin(t *testing.T) {
@@ -64,3 +67,49 @@ func TestLoginInvalidCredentials(t *testing.T) {
 		t.Errorf("login with invalid credentials should have failed")
 	}
 }
+
+func TestLoginOffline(t *testing.T) {
+	server, page := setupTestServer(t)
+
+	loginProvider := NewLoginProvider(page, WithBaseURL(server.URL))
+
+	credentials := Credentials{
+		Email:    edubasetest.Email,
+		Password: edubasetest.Password,
+	}
+
+	if err := loginProvider.Login(credentials, false); err != nil {
+		t.Errorf("login failed: %v", err)
+	}
+}
+
+func TestLoginInvalidCredentialsOffline(t *testing.T) {
+	server, page := setupTestServer(t)
+
+	loginProvider := NewLoginProvider(page, WithBaseURL(server.URL))
+
+	credentials := Credentials{
+		Email:    edubasetest.Email,
+		Password: "wrong",
+	}
+
+	if err := loginProvider.Login(credentials, false); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("got error %v, want %v", err, ErrInvalidCredentials)
+	}
+}
+
+func TestLoginAccountLockedOffline(t *testing.T) {
+	server, page := setupTestServer(t)
+	server.Locked = true
+
+	loginProvider := NewLoginProvider(page, WithBaseURL(server.URL))
+
+	credentials := Credentials{
+		Email:    edubasetest.Email,
+		Password: edubasetest.Password,
+	}
+
+	if err := loginProvider.Login(credentials, false); !errors.Is(err, ErrAccountLocked) {
+		t.Errorf("got error %v, want %v", err, ErrAccountLocked)
+	}
+}
@@ -0,0 +1,96 @@
+package edubase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrMFARequired is returned by the Microsoft login when the account asks
+// for a second factor, e.g. an authenticator app or a code. These prompts
+// need a person and cannot be answered automatically.
+var ErrMFARequired = errors.New("microsoft account requires multi-factor authentication")
+
+// handleMicrosoftLogin follows the redirect to the Microsoft sign in pages,
+// enters email and password and answers the "stay signed in" prompt, until
+// Edubase shows the account button.
+func (l *LoginProvider) handleMicrosoftLogin(ctx context.Context, credentials Credentials) error {
+	loginButton := l.page.Locator(l.selectors.Microsoft.LoginButton).First()
+	if err := l.waitVisible(ctx, loginButton); err != nil {
+		return missingElementError("microsoft login button", err)
+	}
+	if err := await(ctx, func() error {
+		return loginButton.Click()
+	}); err != nil {
+		return fmt.Errorf("could not click microsoft login button: %w", err)
+	}
+
+	// email page
+	if err := l.fillMicrosoftInput(ctx, l.selectors.Microsoft.EmailInput, credentials.Email); err != nil {
+		return missingElementError("microsoft email input", err)
+	}
+
+	passwordInput := l.page.Locator(l.selectors.Microsoft.PasswordInput)
+	usernameError := l.page.Locator(l.selectors.Microsoft.UsernameError)
+	found, err := l.waitForAny(ctx, passwordInput, usernameError)
+	if err != nil {
+		return missingElementError("microsoft password page", err)
+	}
+	if found == usernameError {
+		return loginMessageError(l.textOf(ctx, usernameError))
+	}
+
+	// password page
+	if err := l.fillMicrosoftInput(ctx, l.selectors.Microsoft.PasswordInput, credentials.Password); err != nil {
+		return missingElementError("microsoft password input", err)
+	}
+
+	accountButton := l.getAccountButton()
+	passwordError := l.page.Locator(l.selectors.Microsoft.PasswordError)
+	mfaPrompt := l.page.Locator(l.selectors.Microsoft.MFAPrompt)
+	staySignedIn := l.page.Locator(l.selectors.Microsoft.StaySignedIn)
+
+	found, err = l.waitForAny(ctx, accountButton, passwordError, mfaPrompt, staySignedIn)
+	if err != nil {
+		return missingElementError("next microsoft sign in step", err)
+	}
+
+	switch found {
+	case passwordError:
+		return loginMessageError(l.textOf(ctx, passwordError))
+	case mfaPrompt:
+		return ErrMFARequired
+	case staySignedIn:
+		if err := await(ctx, func() error {
+			return staySignedIn.First().Click()
+		}); err != nil {
+			return fmt.Errorf("could not answer stay signed in prompt: %w", err)
+		}
+	}
+
+	// back on edubase
+	if err := l.waitVisible(ctx, accountButton); err != nil {
+		return missingElementError("account button", err)
+	}
+
+	return nil
+}
+
+// fillMicrosoftInput waits for the input of a Microsoft sign in step, fills
+// it and moves on to the next step.
+func (l *LoginProvider) fillMicrosoftInput(ctx context.Context, selector string, value string) error {
+	input := l.page.Locator(selector)
+	if err := l.waitVisible(ctx, input); err != nil {
+		return err
+	}
+
+	if err := await(ctx, func() error {
+		return input.Fill(value)
+	}); err != nil {
+		return err
+	}
+
+	return await(ctx, func() error {
+		return l.page.Locator(l.selectors.Microsoft.SubmitButton).Click()
+	})
+}
@@ -0,0 +1,54 @@
+package edubase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase/edubasetest"
+)
+
+func TestMicrosoftLoginOffline(t *testing.T) {
+	server, page := setupTestServer(t)
+
+	loginProvider := NewLoginProvider(page, WithBaseURL(server.URL), WithLoginMethod(LoginMethodMicrosoft))
+
+	credentials := Credentials{
+		Email:    edubasetest.Email,
+		Password: edubasetest.Password,
+	}
+
+	if err := loginProvider.Login(credentials, false); err != nil {
+		t.Fatalf("microsoft login failed: %v", err)
+	}
+}
+
+func TestMicrosoftLoginInvalidCredentialsOffline(t *testing.T) {
+	server, page := setupTestServer(t)
+
+	loginProvider := NewLoginProvider(page, WithBaseURL(server.URL), WithLoginMethod(LoginMethodMicrosoft))
+
+	for _, credentials := range []Credentials{
+		{Email: "other@example.com", Password: edubasetest.Password},
+		{Email: edubasetest.Email, Password: "wrong"},
+	} {
+		if err := loginProvider.Login(credentials, false); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("microsoft login with %+v: got error %v, want %v", credentials, err, ErrInvalidCredentials)
+		}
+	}
+}
+
+func TestMicrosoftLoginMFAOffline(t *testing.T) {
+	server, page := setupTestServer(t)
+	server.MicrosoftMFA = true
+
+	loginProvider := NewLoginProvider(page, WithBaseURL(server.URL), WithLoginMethod(LoginMethodMicrosoft))
+
+	credentials := Credentials{
+		Email:    edubasetest.Email,
+		Password: edubasetest.Password,
+	}
+
+	if err := loginProvider.Login(credentials, false); !errors.Is(err, ErrMFARequired) {
+		t.Errorf("got error %v, want %v", err, ErrMFARequired)
+	}
+}
@@ -0,0 +1,129 @@
+package edubase
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the Edubase instance the providers talk to unless
+// configured otherwise.
+const DefaultBaseURL = "https://app.edubase.ch"
+
+// Config holds the settings of the providers. Each provider only uses the
+// fields that apply to it, so a single Config can be shared by all of them.
+type Config struct {
+	// BaseURL is the Edubase instance, without trailing slash.
+	BaseURL string
+	// Timeout bounds how long a provider waits for pages and elements to load.
+	Timeout time.Duration
+
+	// PasswordFillDelay is the pause between filling email and password.
+	PasswordFillDelay time.Duration
+	// VerifyLoginDelay is the pause between submitting the login form and
+	// checking whether the login succeeded.
+	VerifyLoginDelay time.Duration
+	// LoginMethod selects how credentials are used to log in.
+	LoginMethod LoginMethod
+
+	// StabilizationDelay gives the library time to render its last items.
+	StabilizationDelay time.Duration
+
+	// InitialDelay is the pause before the reader is navigated or queried.
+	InitialDelay time.Duration
+
+	// Selectors find the elements of the app.
+	Selectors Selectors
+}
+
+// DefaultConfig returns the settings used for app.edubase.ch.
+func DefaultConfig() Config {
+	return Config{
+		BaseURL:            DefaultBaseURL,
+		Timeout:            15 * time.Second,
+		PasswordFillDelay:  500 * time.Millisecond,
+		VerifyLoginDelay:   500 * time.Millisecond,
+		LoginMethod:        LoginMethodEdubase,
+		StabilizationDelay: 2 * time.Second,
+		InitialDelay:       500 * time.Millisecond,
+		Selectors:          DefaultSelectors(),
+	}
+}
+
+// Option configures a provider.
+type Option func(*Config)
+
+func newConfig(opts []Option) Config {
+	c := DefaultConfig()
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// WithConfig replaces all settings of a provider. Options after it still
+// apply on top.
+func WithConfig(config Config) Option {
+	return func(c *Config) {
+		*c = config
+		c.BaseURL = strings.TrimRight(c.BaseURL, "/")
+	}
+}
+
+// WithBaseURL points a provider at another Edubase instance, e.g. a staging
+// system or a local edubasetest.Server.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Config) {
+		c.BaseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithTimeout sets how long a provider waits for pages and elements to load.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.Timeout = timeout
+	}
+}
+
+// WithPasswordFillDelay sets the pause between filling email and password.
+func WithPasswordFillDelay(delay time.Duration) Option {
+	return func(c *Config) {
+		c.PasswordFillDelay = delay
+	}
+}
+
+// WithVerifyLoginDelay sets the pause before the login result is checked.
+func WithVerifyLoginDelay(delay time.Duration) Option {
+	return func(c *Config) {
+		c.VerifyLoginDelay = delay
+	}
+}
+
+// WithLoginMethod sets how credentials are used to log in.
+func WithLoginMethod(method LoginMethod) Option {
+	return func(c *Config) {
+		c.LoginMethod = method
+	}
+}
+
+// WithStabilizationDelay sets how long the library may take to render its
+// last items.
+func WithStabilizationDelay(delay time.Duration) Option {
+	return func(c *Config) {
+		c.StabilizationDelay = delay
+	}
+}
+
+// WithSelectors replaces the selectors used to find elements of the app,
+// e.g. with the ones of LoadSelectors.
+func WithSelectors(selectors Selectors) Option {
+	return func(c *Config) {
+		c.Selectors = selectors
+	}
+}
+
+// WithInitialDelay sets the pause before the reader is navigated or queried.
+func WithInitialDelay(delay time.Duration) Option {
+	return func(c *Config) {
+		c.InitialDelay = delay
+	}
+}
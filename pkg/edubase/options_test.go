@@ -0,0 +1,71 @@
+package edubase
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewConfigDefaults(t *testing.T) {
+	c := newConfig(nil)
+
+	if c != DefaultConfig() {
+		t.Errorf("newConfig without options = %+v; want %+v", c, DefaultConfig())
+	}
+
+	if c.BaseURL != DefaultBaseURL {
+		t.Errorf("unexpected default base URL: %s", c.BaseURL)
+	}
+}
+
+func TestNewConfigOptions(t *testing.T) {
+	config := DefaultConfig()
+	config.Timeout = time.Minute
+	config.BaseURL = "https://staging.example.com/"
+
+	c := newConfig([]Option{
+		WithInitialDelay(time.Second),
+		WithConfig(config),
+		WithPasswordFillDelay(0),
+		WithVerifyLoginDelay(2 * time.Second),
+		WithStabilizationDelay(3 * time.Second),
+		WithLoginMethod(LoginMethodMicrosoft),
+	})
+
+	want := Config{
+		BaseURL:            "https://staging.example.com",
+		Timeout:            time.Minute,
+		PasswordFillDelay:  0,
+		VerifyLoginDelay:   2 * time.Second,
+		LoginMethod:        LoginMethodMicrosoft,
+		StabilizationDelay: 3 * time.Second,
+		InitialDelay:       DefaultConfig().InitialDelay,
+		Selectors:          DefaultSelectors(),
+	}
+	if c != want {
+		t.Errorf("newConfig = %+v; want %+v", c, want)
+	}
+}
+
+func TestProvidersUseConfig(t *testing.T) {
+	opts := []Option{
+		WithBaseURL("http://localhost:8080/"),
+		WithTimeout(time.Second),
+		WithInitialDelay(0),
+		WithStabilizationDelay(0),
+	}
+
+	loginProvider := NewLoginProvider(nil, opts...)
+	if loginProvider.baseURL != "http://localhost:8080" || loginProvider.timeout != time.Second {
+		t.Errorf("login provider not configured: %+v", loginProvider)
+	}
+
+	libraryProvider := NewLibraryProvider(nil, opts...)
+	if libraryProvider.baseURL != "http://localhost:8080" || libraryProvider.timeout != time.Second || libraryProvider.stabilizationDelay != 0 {
+		t.Errorf("library provider not configured: %+v", libraryProvider)
+	}
+
+	bookProvider := NewBookProvider(nil, 1, opts...)
+	if bookProvider.baseURL != "http://localhost:8080" || bookProvider.initialDelay != 0 {
+		t.Errorf("book provider not configured: %+v", bookProvider)
+	}
+}
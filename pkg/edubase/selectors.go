@@ -0,0 +1,260 @@
+package edubase
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SelectorsVersion is the version of the selector set. It is raised when
+// selectors are added, removed or change their meaning, so override files
+// written for a newer version are rejected instead of half applied.
+const SelectorsVersion = 3
+
+// minSelectorsVersion is the oldest version whose override files still
+// apply. Versions up to SelectorsVersion only added selectors, which an
+// older file gets from the defaults. It has to be raised when a selector is
+// removed or changes its meaning.
+const minSelectorsVersion = 1
+
+// Selectors are the CSS selectors the providers use to find elements of the
+// Edubase app and the Microsoft sign in pages. They can be overridden with
+// LoadSelectors when Edubase changes its UI before the defaults are updated.
+type Selectors struct {
+	Version   int                `yaml:"version"`
+	Login     LoginSelectors     `yaml:"login"`
+	Microsoft MicrosoftSelectors `yaml:"microsoft"`
+	Library   LibrarySelectors   `yaml:"library"`
+	Reader    ReaderSelectors    `yaml:"reader"`
+}
+
+// LoginSelectors find the elements of the login modal and the navbar.
+type LoginSelectors struct {
+	// OpenButton opens the login modal.
+	OpenButton    string `yaml:"open_button"`
+	EmailInput    string `yaml:"email_input"`
+	PasswordInput string `yaml:"password_input"`
+	SubmitButton  string `yaml:"submit_button"`
+	// Error is the error message of the login modal.
+	Error string `yaml:"error"`
+	// AccountButton is only shown when logged in.
+	AccountButton string `yaml:"account_button"`
+}
+
+// MicrosoftSelectors find the elements of the Microsoft sign in pages.
+type MicrosoftSelectors struct {
+	// LoginButton in the login modal redirects to Microsoft.
+	LoginButton   string `yaml:"login_button"`
+	EmailInput    string `yaml:"email_input"`
+	PasswordInput string `yaml:"password_input"`
+	// SubmitButton moves on from every step, so it has to match the one of
+	// the current step only.
+	SubmitButton  string `yaml:"submit_button"`
+	UsernameError string `yaml:"username_error"`
+	PasswordError string `yaml:"password_error"`
+	// StaySignedIn answers the "Stay signed in?" prompt.
+	StaySignedIn string `yaml:"stay_signed_in"`
+	// MFAPrompt matches any prompt for a second factor.
+	MFAPrompt string `yaml:"mfa_prompt"`
+}
+
+// LibrarySelectors find the books of the library.
+type LibrarySelectors struct {
+	Item        string `yaml:"item"`
+	ItemTitle   string `yaml:"item_title"`
+	ItemVersion string `yaml:"item_version"`
+	// ItemAuthor matches each author of an item.
+	ItemAuthor    string `yaml:"item_author"`
+	ItemPublisher string `yaml:"item_publisher"`
+	ItemISBN      string `yaml:"item_isbn"`
+	// ItemIdAttribute is the attribute of an item that holds the book id.
+	ItemIdAttribute string `yaml:"item_id_attribute"`
+}
+
+// ReaderSelectors find the elements of the book reader.
+type ReaderSelectors struct {
+	// TotalPages is the pagination element that shows the page count; the
+	// last match is used.
+	TotalPages     string `yaml:"total_pages"`
+	NextPageButton string `yaml:"next_page_button"`
+	// PageLabel is the page number the reader shows for the current page,
+	// which can differ from its position, e.g. roman numerals.
+	PageLabel string `yaml:"page_label"`
+	// PageContainer holds the SVG of the current page.
+	PageContainer string `yaml:"page_container"`
+	// TOC is the table of contents.
+	TOC string `yaml:"toc"`
+}
+
+// DefaultSelectors returns the selectors for app.edubase.ch.
+func DefaultSelectors() Selectors {
+	return Selectors{
+		Version: SelectorsVersion,
+		Login: LoginSelectors{
+			OpenButton:    "button[data-open='loginModal']",
+			EmailInput:    "input[name='login']",
+			PasswordInput: "input[name='password']",
+			SubmitButton:  "button[type='submit']",
+			Error:         "#loginModal .callout.alert",
+			AccountButton: "#main-navbar > nav > ul.header-controls-nav.d-flex.mr-4 > li:nth-child(5) > div > div.btn.lookup-dropdown.lookup-dropdown_no-space-between.border-0.w-auto.pl-0 > i.svg-icon-user.users-profile-icon.svg-icon-primary__border.mr-2",
+		},
+		Microsoft: MicrosoftSelectors{
+			LoginButton:   "#loginModal a[href*='microsoft']",
+			EmailInput:    "input[name='loginfmt']",
+			PasswordInput: "input[name='passwd']",
+			SubmitButton:  "#idSIButton9:visible",
+			UsernameError: "#usernameError",
+			PasswordError: "#passwordError",
+			// answered with "No"
+			StaySignedIn: "#idBtn_Back:visible",
+			// approve in app, enter a code, choose a method or set up a method
+			MFAPrompt: "#idDiv_SAOTCAS_Title, #idDiv_SAOTCC_Title, #idDiv_SAOTCS_Title, input[name='otc'], #idSubmit_ProofUp_Redirect",
+		},
+		Library: LibrarySelectors{
+			Item:            "#libraryItems > li:not(:first-child)",
+			ItemTitle:       ".lu-library-item-title",
+			ItemVersion:     ".lu-library-item-version",
+			ItemAuthor:      ".lu-library-item-author",
+			ItemPublisher:   ".lu-library-item-publisher",
+			ItemISBN:        ".lu-library-item-isbn",
+			ItemIdAttribute: "data-last-available-version",
+		},
+		Reader: ReaderSelectors{
+			TotalPages:     "#pagination > div > span",
+			NextPageButton: "[data-action='next-page']",
+			PageLabel:      "#pagination > div > div > span",
+			PageContainer:  ".lu-page-svg-container",
+			TOC:            ".lu-toc",
+		},
+	}
+}
+
+// LoadSelectors reads selectors from the YAML file at path. Selectors that
+// are not in the file keep their default, so the file only needs to contain
+// the ones that changed. The file has to state the version it was written
+// for; files of older versions get the selectors added since from the
+// defaults, files of newer versions are rejected.
+func LoadSelectors(path string) (Selectors, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Selectors{}, fmt.Errorf("could not read selectors: %w", err)
+	}
+
+	selectors := DefaultSelectors()
+	selectors.Version = 0
+	if err := yaml.UnmarshalStrict(data, &selectors); err != nil {
+		return Selectors{}, fmt.Errorf("could not parse selectors %s: %w", path, err)
+	}
+
+	if err := checkSelectorsVersion(selectors.Version); err != nil {
+		return Selectors{}, fmt.Errorf("invalid selectors %s: %w", path, err)
+	}
+	selectors.Version = SelectorsVersion
+
+	if err := selectors.Validate(); err != nil {
+		return Selectors{}, fmt.Errorf("invalid selectors %s: %w", path, err)
+	}
+
+	return selectors, nil
+}
+
+// checkSelectorsVersion returns an error if an override file of version
+// cannot be applied to the current selector set.
+func checkSelectorsVersion(version int) error {
+	switch {
+	case version == 0:
+		return fmt.Errorf("version is missing, set it to the version the file was written for (%d is the current one)", SelectorsVersion)
+	case version > SelectorsVersion:
+		return fmt.Errorf("version is %d, but this release only knows versions up to %d, update edubase-to-pdf", version, SelectorsVersion)
+	case version < minSelectorsVersion:
+		return fmt.Errorf("version %d is no longer supported, update the file to version %d", version, SelectorsVersion)
+	}
+	return nil
+}
+
+// Validate checks that the selectors are for this version and that none of
+// them is empty or obviously broken.
+func (s Selectors) Validate() error {
+	var errs []error
+
+	if s.Version != SelectorsVersion {
+		errs = append(errs, fmt.Errorf("version is %d, want %d", s.Version, SelectorsVersion))
+	}
+
+	for _, selector := range []struct {
+		name  string
+		value string
+	}{
+		{"login.open_button", s.Login.OpenButton},
+		{"login.email_input", s.Login.EmailInput},
+		{"login.password_input", s.Login.PasswordInput},
+		{"login.submit_button", s.Login.SubmitButton},
+		{"login.error", s.Login.Error},
+		{"login.account_button", s.Login.AccountButton},
+		{"microsoft.login_button", s.Microsoft.LoginButton},
+		{"microsoft.email_input", s.Microsoft.EmailInput},
+		{"microsoft.password_input", s.Microsoft.PasswordInput},
+		{"microsoft.submit_button", s.Microsoft.SubmitButton},
+		{"microsoft.username_error", s.Microsoft.UsernameError},
+		{"microsoft.password_error", s.Microsoft.PasswordError},
+		{"microsoft.stay_signed_in", s.Microsoft.StaySignedIn},
+		{"microsoft.mfa_prompt", s.Microsoft.MFAPrompt},
+		{"library.item", s.Library.Item},
+		{"library.item_title", s.Library.ItemTitle},
+		{"library.item_version", s.Library.ItemVersion},
+		{"library.item_author", s.Library.ItemAuthor},
+		{"library.item_publisher", s.Library.ItemPublisher},
+		{"library.item_isbn", s.Library.ItemISBN},
+		{"library.item_id_attribute", s.Library.ItemIdAttribute},
+		{"reader.total_pages", s.Reader.TotalPages},
+		{"reader.next_page_button", s.Reader.NextPageButton},
+		{"reader.page_label", s.Reader.PageLabel},
+		{"reader.page_container", s.Reader.PageContainer},
+		{"reader.toc", s.Reader.TOC},
+	} {
+		if err := checkSelector(selector.value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", selector.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkSelector catches empty selectors and unbalanced brackets and quotes,
+// the mistakes most likely when editing a selector by hand.
+func checkSelector(selector string) error {
+	if selector == "" {
+		return errors.New("selector is empty")
+	}
+
+	var open []rune
+	var quote rune
+	for _, r := range selector {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '[' || r == '(':
+			open = append(open, r)
+		case r == ']' || r == ')':
+			if len(open) == 0 || (r == ']') != (open[len(open)-1] == '[') {
+				return fmt.Errorf("unbalanced %q in %q", r, selector)
+			}
+			open = open[:len(open)-1]
+		}
+	}
+
+	if quote != 0 {
+		return fmt.Errorf("unclosed %c in %q", quote, selector)
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("unclosed %q in %q", open[len(open)-1], selector)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package edubase
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSelectors(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "selectors.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDefaultSelectorsValid(t *testing.T) {
+	if err := DefaultSelectors().Validate(); err != nil {
+		t.Errorf("default selectors are invalid: %v", err)
+	}
+}
+
+func TestLoadSelectors(t *testing.T) {
+	path := writeSelectors(t, `version: 3
+login:
+  account_button: "#account"
+reader:
+  next_page_button: "button.next"
+`)
+
+	selectors, err := LoadSelectors(path)
+	if err != nil {
+		t.Fatalf("load selectors failed: %v", err)
+	}
+
+	want := DefaultSelectors()
+	want.Login.AccountButton = "#account"
+	want.Reader.NextPageButton = "button.next"
+	if selectors != want {
+		t.Errorf("got %+v, want %+v", selectors, want)
+	}
+}
+
+func TestLoadSelectorsOlderVersion(t *testing.T) {
+	// written for version 1, before the author, publisher, ISBN and page
+	// label selectors were added
+	path := writeSelectors(t, `version: 1
+library:
+  item_title: "h3.title"
+reader:
+  toc: "nav.toc"
+`)
+
+	selectors, err := LoadSelectors(path)
+	if err != nil {
+		t.Fatalf("load selectors failed: %v", err)
+	}
+
+	want := DefaultSelectors()
+	want.Library.ItemTitle = "h3.title"
+	want.Reader.TOC = "nav.toc"
+	if selectors != want {
+		t.Errorf("got %+v, want %+v", selectors, want)
+	}
+	if err := selectors.Validate(); err != nil {
+		t.Errorf("loaded selectors are invalid: %v", err)
+	}
+}
+
+func TestLoadSelectorsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "missing version", content: "reader:\n  toc: \"#toc\"\n", wantErr: "version is missing"},
+		{name: "newer version", content: "version: 4\n", wantErr: "version is 4, but this release only knows versions up to 3"},
+		{name: "negative version", content: "version: -1\n", wantErr: "version -1 is no longer supported"},
+		{name: "unknown key", content: "version: 3\nreader:\n  next_page: \"#next\"\n", wantErr: "next_page"},
+		{name: "empty selector", content: "version: 3\nreader:\n  toc: \"\"\n", wantErr: "reader.toc: selector is empty"},
+		{name: "unbalanced bracket", content: "version: 3\nlogin:\n  open_button: \"button[data-open='loginModal'\"\n", wantErr: "login.open_button"},
+		{name: "unclosed quote", content: "version: 3\nlogin:\n  email_input: \"input[name='login]\"\n", wantErr: "login.email_input"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadSelectors(writeSelectors(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := LoadSelectors(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestCheckSelector(t *testing.T) {
+	for _, selector := range []string{
+		"#idSIButton9:visible",
+		"#libraryItems > li:not(:first-child)",
+		"a[href*='microsoft'], button:has-text(\"Microsoft ]\")",
+	} {
+		if err := checkSelector(selector); err != nil {
+			t.Errorf("selector %q: %v", selector, err)
+		}
+	}
+
+	for _, selector := range []string{"", "li:not(:first-child", "a[href]]", "a[href='x]", "a(]"} {
+		if err := checkSelector(selector); err == nil {
+			t.Errorf("selector %q: expected error", selector)
+		}
+	}
+}
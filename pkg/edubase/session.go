@@ -0,0 +1,85 @@
+package edubase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/atomicfile"
+	"github.com/playwright-community/playwright-go"
+)
+
+// SaveSession writes the cookies and local storage of the logged in browser
+// context to path, so later runs can skip the login by loading it into a new
+// browser context. The file grants access to the account and is only
+// readable by the current user.
+func (l *LoginProvider) SaveSession(path string) error {
+	return l.SaveSessionContext(context.Background(), path)
+}
+
+// SaveSessionContext is like SaveSession but returns early when ctx is done.
+func (l *LoginProvider) SaveSessionContext(ctx context.Context, path string) error {
+	var state *playwright.StorageState
+	if err := await(ctx, func() (err error) {
+		state, err = l.page.Context().StorageState()
+		return err
+	}); err != nil {
+		return fmt.Errorf("could not get session: %w", err)
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("could not encode session: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("could not create session directory: %w", err)
+	}
+
+	if err := atomicfile.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("could not write session: %w", err)
+	}
+
+	return nil
+}
+
+// IsLoggedIn opens the app and reports whether the browser context is
+// logged in.
+func (l *LoginProvider) IsLoggedIn() (bool, error) {
+	return l.IsLoggedInContext(context.Background())
+}
+
+// IsLoggedInContext opens the app and reports whether the browser context
+// is logged in, e.g. because it was created from a saved session that has
+// not expired yet.
+func (l *LoginProvider) IsLoggedInContext(ctx context.Context) (bool, error) {
+	if err := await(ctx, func() error {
+		_, err := l.page.Goto(l.baseURL)
+		return err
+	}); err != nil {
+		return false, fmt.Errorf("could not go to base page: %w", err)
+	}
+
+	// the app shows either the account button or the login button
+	accountButton := l.getAccountButton()
+	if err := await(ctx, func() error {
+		return accountButton.Or(l.page.Locator(l.selectors.Login.OpenButton)).First().WaitFor(playwright.LocatorWaitForOptions{
+			State:   playwright.WaitForSelectorStateVisible,
+			Timeout: playwright.Float(float64(l.timeout.Milliseconds())),
+		})
+	}); err != nil {
+		return false, fmt.Errorf("could not check login: %w", err)
+	}
+
+	var isVisible bool
+	if err := await(ctx, func() (err error) {
+		isVisible, err = accountButton.IsVisible()
+		return err
+	}); err != nil {
+		return false, fmt.Errorf("could not check login: %w", err)
+	}
+
+	return isVisible, nil
+}
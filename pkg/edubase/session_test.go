@@ -0,0 +1,60 @@
+package edubase
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+func TestSessionOffline(t *testing.T) {
+	server, page := setupTestServerWithLogin(t)
+
+	path := filepath.Join(t.TempDir(), "edubase-to-pdf", "session.json")
+	loginProvider := NewLoginProvider(page, WithBaseURL(server.URL))
+	if err := loginProvider.SaveSession(path); err != nil {
+		t.Fatalf("save session failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("session was not saved: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("session has permissions %o, want 600", perm)
+	}
+
+	// a new browser context starts logged in from the saved session
+	browserContext, err := page.Context().Browser().NewContext(playwright.BrowserNewContextOptions{
+		StorageStatePath: playwright.String(path),
+	})
+	if err != nil {
+		t.Fatalf("could not create browser context from session: %v", err)
+	}
+	defer browserContext.Close()
+
+	sessionPage, err := browserContext.NewPage()
+	if err != nil {
+		t.Fatalf("could not create page: %v", err)
+	}
+
+	sessionLoginProvider := NewLoginProvider(sessionPage, WithBaseURL(server.URL))
+	loggedIn, err := sessionLoginProvider.IsLoggedIn()
+	if err != nil {
+		t.Fatalf("is logged in failed: %v", err)
+	}
+	if !loggedIn {
+		t.Errorf("page with saved session is not logged in")
+	}
+
+	server.ExpireSessions()
+
+	loggedIn, err = sessionLoginProvider.IsLoggedIn()
+	if err != nil {
+		t.Fatalf("is logged in failed: %v", err)
+	}
+	if loggedIn {
+		t.Errorf("page with expired session is logged in")
+	}
+}
@@ -0,0 +1,142 @@
+package edubase
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// exportSVGScript serializes the page SVG of the reader into a standalone
+// document. Referenced images are inlined as data URLs and the @font-face
+// rules of the fonts used by the page are embedded with their font files, so
+// the SVG renders the same outside of the reader.
+const exportSVGScript = `async (container) => {
+	const svgNS = 'http://www.w3.org/2000/svg';
+	const xlinkNS = 'http://www.w3.org/1999/xlink';
+
+	const svg = container.querySelector('svg');
+	if (!svg) {
+		throw new Error('page container has no svg');
+	}
+
+	const toDataURL = async (url) => {
+		const res = await fetch(url, { credentials: 'include' });
+		if (!res.ok) {
+			throw new Error('could not fetch ' + url + ': ' + res.status);
+		}
+		const blob = await res.blob();
+		return await new Promise((resolve, reject) => {
+			const reader = new FileReader();
+			reader.onload = () => resolve(reader.result);
+			reader.onerror = () => reject(reader.error);
+			reader.readAsDataURL(blob);
+		});
+	};
+
+	const unquote = (family) => family.trim().replace(/^["']|["']$/g, '');
+
+	const usedFamilies = new Set();
+	for (const el of svg.querySelectorAll('text, tspan, textPath')) {
+		for (const family of getComputedStyle(el).fontFamily.split(',')) {
+			usedFamilies.add(unquote(family));
+		}
+	}
+
+	const clone = svg.cloneNode(true);
+	const rect = svg.getBoundingClientRect();
+	clone.setAttribute('xmlns', svgNS);
+	clone.setAttribute('xmlns:xlink', xlinkNS);
+	if (!clone.getAttribute('width') || clone.getAttribute('width').endsWith('%')) {
+		clone.setAttribute('width', String(rect.width));
+	}
+	if (!clone.getAttribute('height') || clone.getAttribute('height').endsWith('%')) {
+		clone.setAttribute('height', String(rect.height));
+	}
+
+	for (const image of clone.querySelectorAll('image')) {
+		const href = image.getAttribute('href') || image.getAttributeNS(xlinkNS, 'href');
+		if (!href || href.startsWith('data:')) {
+			continue;
+		}
+		image.setAttribute('href', await toDataURL(new URL(href, document.baseURI).href));
+		image.removeAttributeNS(xlinkNS, 'href');
+	}
+
+	const fontFaces = [];
+	for (const sheet of document.styleSheets) {
+		let rules;
+		try {
+			rules = sheet.cssRules;
+		} catch (e) {
+			// stylesheets from other origins cannot be read
+			continue;
+		}
+		for (const rule of rules) {
+			if (!(rule instanceof CSSFontFaceRule)) {
+				continue;
+			}
+			if (!usedFamilies.has(unquote(rule.style.getPropertyValue('font-family')))) {
+				continue;
+			}
+			let css = rule.cssText;
+			for (const match of rule.cssText.matchAll(/url\(\s*["']?([^"')]+)["']?\s*\)/g)) {
+				if (match[1].startsWith('data:')) {
+					continue;
+				}
+				const dataURL = await toDataURL(new URL(match[1], sheet.href || document.baseURI).href);
+				css = css.replace(match[0], 'url("' + dataURL + '")');
+			}
+			fontFaces.push(css);
+		}
+	}
+
+	if (fontFaces.length > 0) {
+		const style = document.createElementNS(svgNS, 'style');
+		style.textContent = fontFaces.join('\n');
+		clone.insertBefore(style, clone.firstChild);
+	}
+
+	return new XMLSerializer().serializeToString(clone);
+}`
+
+// ExportSVG saves the current page as standalone SVG, which keeps text and
+// graphics as vectors.
+func (b *BookProvider) ExportSVG(filename string) error {
+	return b.ExportSVGContext(context.Background(), filename)
+}
+
+// ExportSVGContext is like ExportSVG but returns early when ctx is done.
+func (b *BookProvider) ExportSVGContext(ctx context.Context, filename string) error {
+	// check if filename is empty
+	if filename == "" {
+		return fmt.Errorf("filename is empty")
+	}
+
+	// check if filename has the correct extension
+	if !strings.EqualFold(filepath.Ext(filename), ".svg") {
+		return fmt.Errorf("filename has the wrong extension")
+	}
+
+	docPage := b.page.Locator(b.selectors.Reader.PageContainer).First()
+
+	var result interface{}
+	if err := await(ctx, func() (err error) {
+		result, err = docPage.Evaluate(exportSVGScript, nil)
+		return err
+	}); err != nil {
+		return fmt.Errorf("could not export svg: %w", err)
+	}
+
+	svg, ok := result.(string)
+	if !ok || svg == "" {
+		return fmt.Errorf("could not export svg: unexpected result %T", result)
+	}
+
+	if err := os.WriteFile(filename, []byte(svg), 0644); err != nil {
+		return fmt.Errorf("could not write svg: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package edubase
+
+import (
+	"testing"
+
+	"github.com/michaelbeutler/edubase-to-pdf/pkg/edubase/edubasetest"
+	"github.com/playwright-community/playwright-go"
+)
+
+// setupTestServer starts a fake Edubase server and a playwright page for
+// offline tests. The test is skipped if playwright cannot be started.
+func setupTestServer(t *testing.T) (*edubasetest.Server, playwright.Page) {
+	t.Helper()
+
+	server := edubasetest.NewServer()
+	t.Cleanup(server.Close)
+
+	page, browser, pw, err := setupTestPlaywright()
+	if err != nil {
+		t.Skipf("Skipping offline test: %v", err)
+	}
+	t.Cleanup(func() {
+		page.Close()
+		browser.Close()
+		pw.Stop()
+	})
+
+	return server, page
+}
+
+// setupTestServerWithLogin is like setupTestServer but also logs in to the
+// fake server with its default account.
+func setupTestServerWithLogin(t *testing.T) (*edubasetest.Server, playwright.Page) {
+	t.Helper()
+
+	server, page := setupTestServer(t)
+
+	loginProvider := NewLoginProvider(page, WithBaseURL(server.URL))
+	credentials := Credentials{
+		Email:    edubasetest.Email,
+		Password: edubasetest.Password,
+	}
+	if err := loginProvider.Login(credentials, false); err != nil {
+		t.Fatalf("failed to login to test server: %v", err)
+	}
+
+	return server, page
+}
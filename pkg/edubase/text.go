@@ -0,0 +1,98 @@
+package edubase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// pageTextScript collects the text runs of the page SVG. Positions are
+// measured on screen, so they match a screenshot of the page container.
+const pageTextScript = `(container) => {
+	const origin = container.getBoundingClientRect();
+	const runs = [];
+
+	for (const el of container.querySelectorAll('text, tspan')) {
+		// only leaves carry their own position, parents are covered by them
+		if (el.querySelector('tspan')) {
+			continue;
+		}
+
+		const text = el.textContent.replace(/\s+/g, ' ').trim();
+		const ctm = el.getScreenCTM();
+		if (!text || !ctm || el.getNumberOfChars() === 0) {
+			continue;
+		}
+
+		const box = el.getBoundingClientRect();
+		if (box.width === 0 || box.height === 0) {
+			continue;
+		}
+
+		let start;
+		try {
+			start = el.getStartPositionOfChar(0).matrixTransform(ctm);
+		} catch (e) {
+			continue;
+		}
+
+		runs.push({
+			text: text,
+			x: start.x - origin.left,
+			y: start.y - origin.top,
+			width: box.width,
+			fontSize: parseFloat(getComputedStyle(el).fontSize) * Math.hypot(ctm.a, ctm.b),
+		});
+	}
+
+	return { width: origin.width, height: origin.height, runs: runs };
+}`
+
+// PageText is the text of a page as shown by the reader.
+type PageText struct {
+	// Width and Height are the size of the page in CSS pixels.
+	Width  float64   `json:"width"`
+	Height float64   `json:"height"`
+	Runs   []TextRun `json:"runs"`
+}
+
+// TextRun is a line or word of text on a page. X and Y are the start of
+// its baseline in CSS pixels from the top-left corner of the page.
+type TextRun struct {
+	Text     string  `json:"text"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Width    float64 `json:"width"`
+	FontSize float64 `json:"fontSize"`
+}
+
+// GetPageText returns the positioned text of the current page.
+func (b *BookProvider) GetPageText() (PageText, error) {
+	return b.GetPageTextContext(context.Background())
+}
+
+// GetPageTextContext is like GetPageText but returns early when ctx is done.
+func (b *BookProvider) GetPageTextContext(ctx context.Context) (PageText, error) {
+	docPage := b.page.Locator(b.selectors.Reader.PageContainer).First()
+
+	var result interface{}
+	if err := await(ctx, func() (err error) {
+		result, err = docPage.Evaluate(pageTextScript, nil)
+		return err
+	}); err != nil {
+		return PageText{}, fmt.Errorf("could not get page text: %w", err)
+	}
+
+	// the result is a generic map, round trip it into the typed struct
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return PageText{}, fmt.Errorf("could not read page text: %v", err)
+	}
+
+	pageText := PageText{}
+	if err := json.Unmarshal(raw, &pageText); err != nil {
+		return PageText{}, fmt.Errorf("could not read page text: %v", err)
+	}
+
+	return pageText, nil
+}
@@ -0,0 +1,43 @@
+package edubase
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetPageTextOffline(t *testing.T) {
+	server, page := setupTestServerWithLogin(t)
+	book := server.Books[0]
+
+	bookProvider := NewBookProvider(page, book.Id, WithBaseURL(server.URL))
+	if err := bookProvider.Open(2); err != nil {
+		t.Fatalf("failed to open book: %v", err)
+	}
+
+	pageText, err := bookProvider.GetPageText()
+	if err != nil {
+		t.Fatalf("get page text failed: %v", err)
+	}
+
+	if pageText.Width == 0 || pageText.Height == 0 {
+		t.Errorf("page has no size: %+v", pageText)
+	}
+
+	texts := []string{}
+	for _, run := range pageText.Runs {
+		if run.FontSize <= 0 || run.Width <= 0 {
+			t.Errorf("run has no size: %+v", run)
+		}
+		if run.X < 0 || run.Y < 0 || run.X > pageText.Width || run.Y > pageText.Height {
+			t.Errorf("run is outside of the page: %+v", run)
+		}
+		texts = append(texts, run.Text)
+	}
+
+	joined := strings.Join(texts, "\n")
+	for _, want := range []string{book.Title, "Page 2 of 38"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("page text %q does not contain %q", joined, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package edubase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// tocScript collects the nested chapter lists of the reader's table of
+// contents. The target page of a chapter is taken from its "#doc/<id>/<page>"
+// link, chapters without one are skipped but their sub-chapters are kept.
+const tocScript = `(toc) => {
+	const parse = (list) => {
+		const entries = [];
+		if (!list) {
+			return entries;
+		}
+
+		for (const item of list.querySelectorAll(':scope > li')) {
+			const link = item.querySelector(':scope > a');
+			const children = parse(item.querySelector(':scope > ul'));
+			const match = link ? (link.getAttribute('href') || '').match(/#doc\/\d+\/(\d+)/) : null;
+			if (!match) {
+				entries.push(...children);
+				continue;
+			}
+
+			entries.push({
+				title: link.textContent.replace(/\s+/g, ' ').trim(),
+				page: Number(match[1]),
+				children: children,
+			});
+		}
+
+		return entries;
+	};
+
+	return parse(toc.querySelector(':scope > ul'));
+}`
+
+// TOCEntry is a chapter of the table of contents of a book.
+type TOCEntry struct {
+	Title string `json:"title"`
+	// Page is the page of the book the chapter starts on.
+	Page     int        `json:"page"`
+	Children []TOCEntry `json:"children"`
+}
+
+// GetTableOfContents returns the table of contents of the open book. Books
+// without a table of contents return an empty list.
+func (b *BookProvider) GetTableOfContents() ([]TOCEntry, error) {
+	return b.GetTableOfContentsContext(context.Background())
+}
+
+// GetTableOfContentsContext is like GetTableOfContents but returns early when
+// ctx is done.
+func (b *BookProvider) GetTableOfContentsContext(ctx context.Context) ([]TOCEntry, error) {
+	toc := b.page.Locator(b.selectors.Reader.TOC)
+
+	var count int
+	if err := await(ctx, func() (err error) {
+		count, err = toc.Count()
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("could not get table of contents: %w", err)
+	}
+
+	if count == 0 {
+		return []TOCEntry{}, nil
+	}
+
+	var result interface{}
+	if err := await(ctx, func() (err error) {
+		result, err = toc.First().Evaluate(tocScript, nil)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("could not get table of contents: %w", err)
+	}
+
+	// the result is a generic list, round trip it into the typed struct
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("could not read table of contents: %v", err)
+	}
+
+	entries := []TOCEntry{}
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("could not read table of contents: %v", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,52 @@
+package edubase
+
+import (
+	"testing"
+)
+
+func TestGetTableOfContentsOffline(t *testing.T) {
+	server, page := setupTestServerWithLogin(t)
+	book := server.Books[0]
+
+	bookProvider := NewBookProvider(page, book.Id, WithBaseURL(server.URL))
+	if err := bookProvider.Open(1); err != nil {
+		t.Fatalf("failed to open book: %v", err)
+	}
+
+	toc, err := bookProvider.GetTableOfContents()
+	if err != nil {
+		t.Fatalf("get table of contents failed: %v", err)
+	}
+
+	if len(toc) != len(book.TOC) {
+		t.Fatalf("got %d chapters, want %d: %+v", len(toc), len(book.TOC), toc)
+	}
+
+	for i, chapter := range book.TOC {
+		if toc[i].Title != chapter.Title || toc[i].Page != chapter.Page {
+			t.Errorf("chapter %d: got %q on page %d, want %q on page %d", i, toc[i].Title, toc[i].Page, chapter.Title, chapter.Page)
+		}
+		if len(toc[i].Children) != len(chapter.Children) {
+			t.Errorf("chapter %d: got %d sub-chapters, want %d", i, len(toc[i].Children), len(chapter.Children))
+		}
+	}
+}
+
+func TestGetTableOfContentsEmptyOffline(t *testing.T) {
+	server, page := setupTestServerWithLogin(t)
+	book := server.Books[1]
+
+	bookProvider := NewBookProvider(page, book.Id, WithBaseURL(server.URL))
+	if err := bookProvider.Open(1); err != nil {
+		t.Fatalf("failed to open book: %v", err)
+	}
+
+	toc, err := bookProvider.GetTableOfContents()
+	if err != nil {
+		t.Fatalf("get table of contents failed: %v", err)
+	}
+
+	if len(toc) != 0 {
+		t.Errorf("got %d chapters, want none", len(toc))
+	}
+}
@@ -0,0 +1,157 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Metadata describes a document. It is written into the document
+// information dictionary, which most viewers show as document properties,
+// and as XMP metadata, which document management systems index. Empty
+// fields are left out.
+type Metadata struct {
+	Title     string
+	Authors   []string
+	Subject   string
+	Keywords  []string
+	Publisher string
+	ISBN      string
+	Edition   string
+	// Creator is the application that created the document.
+	Creator string
+}
+
+// Info returns the entries of the document information dictionary. Title,
+// Author, Subject, Keywords and Creator are standard entries, Publisher,
+// ISBN and Edition are custom ones.
+func (m Metadata) Info() map[string]string {
+	info := map[string]string{}
+	for key, value := range map[string]string{
+		"Title":     m.Title,
+		"Author":    strings.Join(m.Authors, ", "),
+		"Subject":   m.Subject,
+		"Keywords":  strings.Join(m.Keywords, ", "),
+		"Creator":   m.Creator,
+		"Publisher": m.Publisher,
+		"ISBN":      m.ISBN,
+		"Edition":   m.Edition,
+	} {
+		if value != "" {
+			info[key] = value
+		}
+	}
+	return info
+}
+
+// XMP returns the metadata as XMP packet, using Dublin Core for the
+// standard fields and PRISM for ISBN and edition.
+func (m Metadata) XMP() []byte {
+	var b bytes.Buffer
+	b.WriteString("<?xpacket begin=\"\xef\xbb\xbf\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	b.WriteString("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n")
+	b.WriteString("<rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\">\n")
+	b.WriteString("<rdf:Description rdf:about=\"\"" +
+		" xmlns:dc=\"http://purl.org/dc/elements/1.1/\"" +
+		" xmlns:pdf=\"http://ns.adobe.com/pdf/1.3/\"" +
+		" xmlns:xmp=\"http://ns.adobe.com/xap/1.0/\"" +
+		" xmlns:prism=\"http://prismstandard.org/namespaces/basic/2.0/\">\n")
+
+	writeXMPList(&b, "dc:title", "rdf:Alt", m.Title)
+	writeXMPList(&b, "dc:creator", "rdf:Seq", m.Authors...)
+	writeXMPList(&b, "dc:description", "rdf:Alt", m.Subject)
+	writeXMPList(&b, "dc:subject", "rdf:Bag", m.Keywords...)
+	writeXMPList(&b, "dc:publisher", "rdf:Bag", m.Publisher)
+	if m.ISBN != "" {
+		writeXMPProperty(&b, "dc:identifier", "urn:isbn:"+m.ISBN)
+	}
+	writeXMPProperty(&b, "pdf:Keywords", strings.Join(m.Keywords, ", "))
+	writeXMPProperty(&b, "xmp:CreatorTool", m.Creator)
+	writeXMPProperty(&b, "prism:isbn", m.ISBN)
+	writeXMPProperty(&b, "prism:edition", m.Edition)
+
+	b.WriteString("</rdf:Description>\n</rdf:RDF>\n</x:xmpmeta>\n")
+	b.WriteString("<?xpacket end=\"w\"?>")
+	return b.Bytes()
+}
+
+// writeXMPProperty writes a simple property, unless value is empty.
+func writeXMPProperty(b *bytes.Buffer, name string, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b, "<%s>", name)
+	xml.EscapeText(b, []byte(value))
+	fmt.Fprintf(b, "</%s>\n", name)
+}
+
+// writeXMPList writes a property holding a list of the given kind. The items
+// of a language alternative are the default language. Empty items are left
+// out and so is the property if there are none.
+func writeXMPList(b *bytes.Buffer, name string, kind string, items ...string) {
+	var values []string
+	for _, item := range items {
+		if item != "" {
+			values = append(values, item)
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "<%s><%s>", name, kind)
+	for _, value := range values {
+		if kind == "rdf:Alt" {
+			b.WriteString("<rdf:li xml:lang=\"x-default\">")
+		} else {
+			b.WriteString("<rdf:li>")
+		}
+		xml.EscapeText(b, []byte(value))
+		b.WriteString("</rdf:li>")
+	}
+	fmt.Fprintf(b, "</%s></%s>\n", kind, name)
+}
+
+// writeMetadata writes the information dictionary and the XMP metadata
+// stream and returns their object numbers.
+func (w *Writer) writeMetadata(m Metadata) (int, int, error) {
+	info := m.Info()
+	keys := make([]string, 0, len(info))
+	for key := range info {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var dict strings.Builder
+	dict.WriteString("<<")
+	for _, key := range keys {
+		fmt.Fprintf(&dict, " /%s %s", key, encodeTextString(info[key]))
+	}
+	dict.WriteString(" >>")
+
+	infoObject := w.newObject()
+	if err := w.writeObject(infoObject, dict.String()); err != nil {
+		return 0, 0, err
+	}
+
+	// XMP metadata must not be compressed, so it can be found without a PDF
+	// parser
+	xmp := m.XMP()
+	metadataObject := w.newObject()
+	if err := w.startObject(metadataObject); err != nil {
+		return 0, 0, err
+	}
+	if _, err := fmt.Fprintf(w.w, "<< /Type /Metadata /Subtype /XML /Length %d >>\nstream\n", len(xmp)); err != nil {
+		return 0, 0, err
+	}
+	if _, err := w.w.Write(xmp); err != nil {
+		return 0, 0, err
+	}
+	if err := w.endStream(); err != nil {
+		return 0, 0, err
+	}
+
+	return infoObject, metadataObject, nil
+}
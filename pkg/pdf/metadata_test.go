@@ -0,0 +1,116 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testMetadata = Metadata{
+	Title:     "Mathematik 1",
+	Authors:   []string{"Anna Muster", "Beat Beispiel"},
+	Subject:   "3. Auflage 2023, Lehrmittelverlag Zürich",
+	Keywords:  []string{"ISBN 978-3-03713-123-4", "Edubase 58216"},
+	Publisher: "Lehrmittelverlag Zürich",
+	ISBN:      "978-3-03713-123-4",
+	Edition:   "3. Auflage 2023",
+	Creator:   "edubase-to-pdf",
+}
+
+func TestMetadataInfo(t *testing.T) {
+	want := map[string]string{
+		"Title":     "Mathematik 1",
+		"Author":    "Anna Muster, Beat Beispiel",
+		"Subject":   "3. Auflage 2023, Lehrmittelverlag Zürich",
+		"Keywords":  "ISBN 978-3-03713-123-4, Edubase 58216",
+		"Creator":   "edubase-to-pdf",
+		"Publisher": "Lehrmittelverlag Zürich",
+		"ISBN":      "978-3-03713-123-4",
+		"Edition":   "3. Auflage 2023",
+	}
+	if got := testMetadata.Info(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// empty fields are left out
+	want = map[string]string{"Title": "Deutsch"}
+	if got := (Metadata{Title: "Deutsch"}).Info(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestMetadataXMP(t *testing.T) {
+	metadata := testMetadata
+	metadata.Title = "Grammatik <Übungen> & Tests"
+	xmp := metadata.XMP()
+
+	// the packet must be well-formed XML
+	decoder := xml.NewDecoder(bytes.NewReader(xmp))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid XMP: %v\n%s", err, xmp)
+		}
+	}
+
+	for _, want := range []string{
+		`<dc:title><rdf:Alt><rdf:li xml:lang="x-default">Grammatik &lt;Übungen&gt; &amp; Tests</rdf:li></rdf:Alt></dc:title>`,
+		`<dc:creator><rdf:Seq><rdf:li>Anna Muster</rdf:li><rdf:li>Beat Beispiel</rdf:li></rdf:Seq></dc:creator>`,
+		`<dc:publisher><rdf:Bag><rdf:li>Lehrmittelverlag Zürich</rdf:li></rdf:Bag></dc:publisher>`,
+		`<dc:identifier>urn:isbn:978-3-03713-123-4</dc:identifier>`,
+		`<prism:edition>3. Auflage 2023</prism:edition>`,
+	} {
+		if !strings.Contains(string(xmp), want) {
+			t.Errorf("XMP does not contain %s:\n%s", want, xmp)
+		}
+	}
+
+	if strings.Contains(string((Metadata{Title: "Deutsch"}).XMP()), "dc:creator") {
+		t.Errorf("XMP of metadata without authors should have no dc:creator")
+	}
+}
+
+func TestWriterMetadata(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "page.jpeg")
+	writeTestJPEG(t, filename, 50, 50)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("could not create writer: %v", err)
+	}
+	if err := w.AddJPEGPage(filename, TextLayer{}); err != nil {
+		t.Fatalf("could not add page: %v", err)
+	}
+	w.SetMetadata(testMetadata)
+	if err := w.Close(); err != nil {
+		t.Fatalf("could not close writer: %v", err)
+	}
+
+	ctx := readTestPDF(t, buf.Bytes())
+	if ctx.Title != testMetadata.Title {
+		t.Errorf("got title %q, want %q", ctx.Title, testMetadata.Title)
+	}
+	if ctx.Author != "Anna Muster, Beat Beispiel" {
+		t.Errorf("got author %q, want %q", ctx.Author, "Anna Muster, Beat Beispiel")
+	}
+	if ctx.Properties["ISBN"] != testMetadata.ISBN {
+		t.Errorf("got ISBN %q, want %q", ctx.Properties["ISBN"], testMetadata.ISBN)
+	}
+
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("could not read catalog: %v", err)
+	}
+	if catalog.IndirectRefEntry("Metadata") == nil {
+		t.Errorf("catalog has no XMP metadata")
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("<prism:isbn>978-3-03713-123-4</prism:isbn>")) {
+		t.Errorf("XMP metadata is missing or compressed")
+	}
+}
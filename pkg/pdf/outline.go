@@ -0,0 +1,83 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// Bookmark is an entry of the outline of a document. Page is the number of
+// the page it points to, starting at 1.
+type Bookmark struct {
+	Title string
+	Page  int
+	Kids  []Bookmark
+}
+
+// writeOutline writes the outline dictionary and its items and returns the
+// number of the outline dictionary.
+func (w *Writer) writeOutline(bookmarks []Bookmark) (int, error) {
+	root := w.newObject()
+
+	first, last, err := w.writeOutlineItems(bookmarks, root)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := w.writeObject(root, fmt.Sprintf("<< /Type /Outlines /First %d 0 R /Last %d 0 R /Count %d >>", first, last, len(bookmarks))); err != nil {
+		return 0, err
+	}
+
+	return root, nil
+}
+
+// writeOutlineItems writes the items of a list of sibling bookmarks and
+// returns the numbers of the first and last item. Items with kids start
+// collapsed, so chapters do not flood the outline of large books.
+func (w *Writer) writeOutlineItems(bookmarks []Bookmark, parent int) (int, int, error) {
+	objects := make([]int, len(bookmarks))
+	for i := range bookmarks {
+		objects[i] = w.newObject()
+	}
+
+	for i, bookmark := range bookmarks {
+		if bookmark.Page < 1 || bookmark.Page > len(w.pages) {
+			return 0, 0, fmt.Errorf("pdf: bookmark %q points to page %d, but document has %d pages", bookmark.Title, bookmark.Page, len(w.pages))
+		}
+
+		var item strings.Builder
+		fmt.Fprintf(&item, "<< /Title %s /Parent %d 0 R /Dest [%d 0 R /Fit]", encodeTextString(bookmark.Title), parent, w.pages[bookmark.Page-1])
+		if i > 0 {
+			fmt.Fprintf(&item, " /Prev %d 0 R", objects[i-1])
+		}
+		if i < len(bookmarks)-1 {
+			fmt.Fprintf(&item, " /Next %d 0 R", objects[i+1])
+		}
+		if len(bookmark.Kids) > 0 {
+			first, last, err := w.writeOutlineItems(bookmark.Kids, objects[i])
+			if err != nil {
+				return 0, 0, err
+			}
+			fmt.Fprintf(&item, " /First %d 0 R /Last %d 0 R /Count -%d", first, last, len(bookmark.Kids))
+		}
+		item.WriteString(" >>")
+
+		if err := w.writeObject(objects[i], item.String()); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return objects[0], objects[len(objects)-1], nil
+}
+
+// encodeTextString encodes s as PDF text string in UTF-16 with byte order
+// mark, which viewers display in any language.
+func encodeTextString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, c := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", c)
+	}
+	b.WriteString(">")
+	return b.String()
+}
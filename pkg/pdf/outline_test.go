@@ -0,0 +1,84 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	pdfcpu "github.com/pdfcpu/pdfcpu/pkg/api"
+	pdfcore "github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// outlineString flattens bookmarks read by pdfcpu for easy comparison.
+func outlineString(bookmarks []pdfcore.Bookmark) string {
+	s := ""
+	for _, bookmark := range bookmarks {
+		s += fmt.Sprintf("[%s:%d%s]", bookmark.Title, bookmark.PageFrom, outlineString(bookmark.Kids))
+	}
+	return s
+}
+
+// writeTestDocument writes a document of pageCount pages with the given
+// outline.
+func writeTestDocument(t *testing.T, pageCount int, bookmarks []Bookmark) ([]byte, error) {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "page.jpeg")
+	writeTestJPEG(t, filename, 10, 10)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("could not create writer: %v", err)
+	}
+	for i := 0; i < pageCount; i++ {
+		if err := w.AddJPEGPage(filename, TextLayer{}); err != nil {
+			t.Fatalf("could not add page %d: %v", i+1, err)
+		}
+	}
+
+	w.SetOutline(bookmarks)
+	err = w.Close()
+	return buf.Bytes(), err
+}
+
+func TestOutline(t *testing.T) {
+	bookmarks := []Bookmark{
+		{Title: "1 Zahlen", Page: 1, Kids: []Bookmark{
+			{Title: "1.1 Natürliche Zahlen", Page: 2},
+			{Title: "1.2 Brüche", Page: 3},
+		}},
+		{Title: "2 Geometrie", Page: 4},
+		{Title: "3 Algebra „x²“", Page: 5},
+	}
+
+	data, err := writeTestDocument(t, 5, bookmarks)
+	if err != nil {
+		t.Fatalf("could not write document: %v", err)
+	}
+
+	readTestPDF(t, data)
+
+	read, err := pdfcpu.Bookmarks(bytes.NewReader(data), nil)
+	if err != nil {
+		t.Fatalf("could not read bookmarks: %v", err)
+	}
+
+	want := "[1 Zahlen:1[1.1 Natürliche Zahlen:2][1.2 Brüche:3]][2 Geometrie:4][3 Algebra „x²“:5]"
+	if got := outlineString(read); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestOutlinePageOutOfRange(t *testing.T) {
+	if _, err := writeTestDocument(t, 2, []Bookmark{{Title: "Anhang", Page: 3}}); err == nil {
+		t.Errorf("bookmark to a missing page should have failed")
+	}
+}
+
+func TestEncodeTextString(t *testing.T) {
+	if got, want := encodeTextString("Aü€"), "<FEFF004100FC20AC>"; got != want {
+		t.Errorf("encodeTextString = %s; want %s", got, want)
+	}
+}
@@ -0,0 +1,148 @@
+package pdf
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Numbering styles of page labels.
+const (
+	LabelDecimal    = "D"
+	LabelRomanLower = "r"
+	LabelRomanUpper = "R"
+)
+
+// PageLabelRange labels the pages from Page, starting at 1, up to the next
+// range. The label of a page is Prefix followed by its number in Style,
+// counting from Start. Without Style the label is just Prefix.
+type PageLabelRange struct {
+	Page   int
+	Style  string
+	Prefix string
+	Start  int
+}
+
+var decimalLabel = regexp.MustCompile(`^(.*?)([0-9]+)$`)
+
+// PageLabelRanges returns the ranges that label the pages of a document
+// with labels, one label per page. Labels that count up in the same style
+// and with the same prefix share a range. Roman numerals are recognized
+// only if they make up the whole label, so words like "Index" stay words.
+func PageLabelRanges(labels []string) []PageLabelRange {
+	ranges := []PageLabelRange{}
+
+	var previous int
+	for i, label := range labels {
+		style, prefix, n := parsePageLabel(label)
+
+		if len(ranges) > 0 {
+			last := ranges[len(ranges)-1]
+			if style != "" && style == last.Style && prefix == last.Prefix && n == previous+1 {
+				previous = n
+				continue
+			}
+		}
+
+		r := PageLabelRange{Page: i + 1, Style: style, Prefix: prefix, Start: n}
+		if style == "" {
+			r.Prefix, r.Start = label, 0
+		}
+		ranges = append(ranges, r)
+		previous = n
+	}
+
+	return ranges
+}
+
+// parsePageLabel splits label into its style, prefix and number. The style
+// is empty if label does not end with a number.
+func parsePageLabel(label string) (string, string, int) {
+	if m := decimalLabel.FindStringSubmatch(label); m != nil {
+		// numbers with leading zeros cannot be told apart from the ones
+		// without, so keep them as text
+		if n, err := strconv.Atoi(m[2]); err == nil && n > 0 && !strings.HasPrefix(m[2], "0") {
+			return LabelDecimal, m[1], n
+		}
+		return "", "", 0
+	}
+
+	if n := parseRoman(strings.ToLower(label)); n > 0 {
+		if label == strings.ToLower(label) {
+			return LabelRomanLower, "", n
+		}
+		if label == strings.ToUpper(label) {
+			return LabelRomanUpper, "", n
+		}
+	}
+
+	return "", "", 0
+}
+
+var romanNumerals = []struct {
+	value   int
+	numeral string
+}{
+	{1000, "m"}, {900, "cm"}, {500, "d"}, {400, "cd"},
+	{100, "c"}, {90, "xc"}, {50, "l"}, {40, "xl"},
+	{10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"},
+}
+
+// parseRoman returns the value of the lower case roman numeral s, or 0 if
+// s is not one in canonical form.
+func parseRoman(s string) int {
+	n := 0
+	rest := s
+	for _, r := range romanNumerals {
+		for strings.HasPrefix(rest, r.numeral) {
+			n += r.value
+			rest = rest[len(r.numeral):]
+		}
+	}
+	if s == "" || rest != "" || formatRoman(n) != s {
+		return 0
+	}
+	return n
+}
+
+func formatRoman(n int) string {
+	var b strings.Builder
+	for _, r := range romanNumerals {
+		for n >= r.value {
+			b.WriteString(r.numeral)
+			n -= r.value
+		}
+	}
+	return b.String()
+}
+
+// SetPageLabels sets the labels viewers show instead of the page numbers,
+// one per page. They are written on Close.
+func (w *Writer) SetPageLabels(labels []string) {
+	w.pageLabels = labels
+}
+
+// pageLabelsDict returns the page labels number tree for the catalog.
+func (w *Writer) pageLabelsDict() (string, error) {
+	if len(w.pageLabels) != len(w.pages) {
+		return "", fmt.Errorf("pdf: got %d page labels, but document has %d pages", len(w.pageLabels), len(w.pages))
+	}
+
+	var nums strings.Builder
+	for _, r := range PageLabelRanges(w.pageLabels) {
+		fmt.Fprintf(&nums, " %d <<", r.Page-1)
+		if r.Style != "" {
+			fmt.Fprintf(&nums, " /S /%s", r.Style)
+		}
+		if r.Prefix != "" {
+			fmt.Fprintf(&nums, " /P %s", encodeTextString(r.Prefix))
+		}
+		if r.Style != "" && r.Start != 1 {
+			fmt.Fprintf(&nums, " /St %d", r.Start)
+		}
+		nums.WriteString(" >>")
+	}
+
+	return fmt.Sprintf("<< /Nums [%s ] >>", nums.String()), nil
+}
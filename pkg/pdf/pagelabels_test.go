@@ -0,0 +1,132 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+func TestPageLabelRanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels []string
+		want   []PageLabelRange
+	}{
+		{
+			name:   "front matter",
+			labels: []string{"i", "ii", "iii", "iv", "1", "2", "3"},
+			want: []PageLabelRange{
+				{Page: 1, Style: LabelRomanLower, Start: 1},
+				{Page: 5, Style: LabelDecimal, Start: 1},
+			},
+		},
+		{
+			name:   "cover and appendix",
+			labels: []string{"Cover", "Index", "XII", "XIII", "A-1", "A-2", "B-1"},
+			want: []PageLabelRange{
+				{Page: 1, Prefix: "Cover"},
+				{Page: 2, Prefix: "Index"},
+				{Page: 3, Style: LabelRomanUpper, Start: 12},
+				{Page: 5, Style: LabelDecimal, Prefix: "A-", Start: 1},
+				{Page: 7, Style: LabelDecimal, Prefix: "B-", Start: 1},
+			},
+		},
+		{
+			name:   "gaps and leading zeros",
+			labels: []string{"5", "6", "40", "007", "iiii"},
+			want: []PageLabelRange{
+				{Page: 1, Style: LabelDecimal, Start: 5},
+				{Page: 3, Style: LabelDecimal, Start: 40},
+				{Page: 4, Prefix: "007"},
+				{Page: 5, Prefix: "iiii"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PageLabelRanges(tt.labels); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRoman(t *testing.T) {
+	for n := 1; n < 4000; n++ {
+		if got := parseRoman(formatRoman(n)); got != n {
+			t.Fatalf("parseRoman(%q) = %d, want %d", formatRoman(n), got, n)
+		}
+	}
+
+	for _, s := range []string{"", "iiii", "vx", "ic", "abc"} {
+		if got := parseRoman(s); got != 0 {
+			t.Errorf("parseRoman(%q) = %d, want 0", s, got)
+		}
+	}
+}
+
+func TestWriterPageLabels(t *testing.T) {
+	dir := t.TempDir()
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("could not create writer: %v", err)
+	}
+
+	labels := []string{"i", "ii", "1", "2"}
+	for i := range labels {
+		filename := filepath.Join(dir, fmt.Sprintf("%d.jpeg", i))
+		writeTestJPEG(t, filename, 50, 50)
+		if err := w.AddJPEGPage(filename, TextLayer{}); err != nil {
+			t.Fatalf("could not add page: %v", err)
+		}
+	}
+
+	w.SetPageLabels(labels)
+	if err := w.Close(); err != nil {
+		t.Fatalf("could not close writer: %v", err)
+	}
+
+	ctx := readTestPDF(t, buf.Bytes())
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		t.Fatalf("could not read catalog: %v", err)
+	}
+	pageLabels := catalog.DictEntry("PageLabels")
+	if pageLabels == nil {
+		t.Fatalf("catalog has no page labels")
+	}
+
+	nums := pageLabels.ArrayEntry("Nums")
+	want := types.Array{
+		types.Integer(0), types.Dict{"S": types.Name("r")},
+		types.Integer(2), types.Dict{"S": types.Name("D")},
+	}
+	if nums.String() != want.String() {
+		t.Errorf("got page labels %s, want %s", nums, want)
+	}
+}
+
+func TestWriterPageLabelsCount(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "page.jpeg")
+	writeTestJPEG(t, filename, 50, 50)
+
+	w, err := NewWriter(&bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("could not create writer: %v", err)
+	}
+	if err := w.AddJPEGPage(filename, TextLayer{}); err != nil {
+		t.Fatalf("could not add page: %v", err)
+	}
+
+	w.SetPageLabels([]string{"1", "2"})
+	if err := w.Close(); err == nil {
+		t.Errorf("closing with more labels than pages should have failed")
+	}
+}
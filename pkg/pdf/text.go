@@ -0,0 +1,120 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/pdfcpu/pdfcpu/pkg/font"
+	"golang.org/x/text/encoding/charmap"
+)
+
+const (
+	// textLayerFont is a standard font every PDF viewer has, so it does not
+	// need to be embedded. The text is invisible, only its metrics matter.
+	textLayerFont = "Helvetica"
+	// textLayerFontResource is the resource name of the font on each page.
+	textLayerFontResource = "EdubaseText"
+)
+
+// TextLayer is the invisible text of a page. Its coordinates are relative to
+// a box of Width x Height units with the origin in the top-left corner, which
+// is stretched over the whole page.
+type TextLayer struct {
+	Width  float64
+	Height float64
+	Runs   []TextRun
+}
+
+// TextRun is a line or word of text. X and Y are the start of its baseline.
+type TextRun struct {
+	Text     string
+	X        float64
+	Y        float64
+	Width    float64
+	FontSize float64
+}
+
+// content returns the content stream operators that draw the text runs
+// invisibly (render mode 3) on a page of the given size in points.
+func (t TextLayer) content(pageWidth, pageHeight float64) []byte {
+	if len(t.Runs) == 0 || t.Width <= 0 || t.Height <= 0 {
+		return nil
+	}
+
+	scaleX := pageWidth / t.Width
+	scaleY := pageHeight / t.Height
+
+	var buf bytes.Buffer
+	buf.WriteString("q BT 3 Tr\n")
+
+	for _, run := range t.Runs {
+		text := encodeWinAnsi(run.Text)
+		fontSize := run.FontSize * scaleY
+		if len(text) == 0 || fontSize <= 0 {
+			continue
+		}
+
+		// stretch the text horizontally so selections match the page image
+		scaling := 100.0
+		if naturalWidth := textWidth(text) * fontSize; naturalWidth > 0 && run.Width > 0 {
+			scaling = run.Width * scaleX / naturalWidth * 100
+		}
+
+		x := run.X * scaleX
+		y := pageHeight - run.Y*scaleY
+
+		fmt.Fprintf(&buf, "/%s %.2f Tf %.2f Tz 1 0 0 1 %.2f %.2f Tm (%s) Tj\n", textLayerFontResource, fontSize, scaling, x, y, escapeString(text))
+	}
+
+	buf.WriteString("ET Q\n")
+	return buf.Bytes()
+}
+
+// encodeWinAnsi encodes s for a font with WinAnsiEncoding. Characters the
+// encoding lacks are replaced by '?'.
+func encodeWinAnsi(s string) []byte {
+	encoded := make([]byte, 0, len(s))
+	for _, r := range s {
+		b, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			b = '?'
+		}
+		encoded = append(encoded, b)
+	}
+	return encoded
+}
+
+// textWidth returns the width of the WinAnsi encoded text in the text layer
+// font at font size 1. The widths are looked up by WinAnsi code, so
+// characters like "ü" are measured as the glyph the viewer draws.
+func textWidth(text []byte) float64 {
+	width := 0
+	for _, c := range text {
+		width += font.CharWidth(textLayerFont, rune(c))
+	}
+	return float64(width) / 1000
+}
+
+// escapeString escapes b for use in a literal PDF string.
+func escapeString(b []byte) []byte {
+	escaped := make([]byte, 0, len(b))
+	for _, c := range b {
+		switch c {
+		case '\\', '(', ')':
+			escaped = append(escaped, '\\', c)
+		case '\r':
+			escaped = append(escaped, '\\', 'r')
+		case '\n':
+			escaped = append(escaped, '\\', 'n')
+		default:
+			escaped = append(escaped, c)
+		}
+	}
+	return escaped
+}
+
+// formatNumber formats f as a PDF number, which has no exponent notation.
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,144 @@
+package pdf
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTextLayer(t *testing.T) {
+	dir := t.TempDir()
+
+	images := []string{filepath.Join(dir, "1.jpeg"), filepath.Join(dir, "2.jpeg")}
+	for _, filename := range images {
+		writeTestJPEG(t, filename, 119, 168)
+	}
+
+	textLayers := []TextLayer{
+		{
+			Width:  238,
+			Height: 336,
+			Runs: []TextRun{
+				{Text: "Grüße (Kapitel 1)", X: 24, Y: 40, Width: 160, FontSize: 12},
+				{Text: "Übungen", X: 24, Y: 80, Width: 60, FontSize: 8},
+			},
+		},
+		{},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("could not create writer: %v", err)
+	}
+	for i, filename := range images {
+		if err := w.AddJPEGPage(filename, textLayers[i]); err != nil {
+			t.Fatalf("could not add page %d: %v", i+1, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("could not close writer: %v", err)
+	}
+
+	ctx := readTestPDF(t, buf.Bytes())
+
+	pageDict, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatalf("could not get page: %v", err)
+	}
+
+	content, err := ctx.PageContent(pageDict)
+	if err != nil {
+		t.Fatalf("could not get page content: %v", err)
+	}
+
+	// the text layer is half the size of the page, so positions are halved
+	for _, want := range [][]byte{[]byte("3 Tr"), []byte("/EdubaseText 6.00 Tf"), []byte("12.00 148.00 Tm"), encodeWinAnsi(`(Gr` + "üß" + `e \(Kapitel 1\))`)} {
+		if !bytes.Contains(content, want) {
+			t.Errorf("page content does not contain %q:\n%s", want, content)
+		}
+	}
+
+	pageDict, _, _, err = ctx.PageDict(2, false)
+	if err != nil {
+		t.Fatalf("could not get page: %v", err)
+	}
+
+	content, err = ctx.PageContent(pageDict)
+	if err != nil {
+		t.Fatalf("could not get page content: %v", err)
+	}
+
+	if bytes.Contains(content, []byte("Tj")) {
+		t.Errorf("page without text got a text layer:\n%s", content)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"Gruse", 2.723},
+		// G, r, ü, ß and e of Helvetica
+		{"Grüße", 2.834},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := textWidth(encodeWinAnsi(tt.input)); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("textWidth(%q) = %v; want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeWinAnsi(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"Grüße", "Gr\xfc\xdfe"},
+		{"€ – „Zitat“", "\x80 \x96 \x84Zitat\x93"},
+		{"日本", "??"},
+	}
+
+	for _, tt := range tests {
+		result := string(encodeWinAnsi(tt.input))
+		if result != tt.expected {
+			t.Errorf("encodeWinAnsi(%q) = %q; want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestEscapeString(t *testing.T) {
+	input := `a (b) c\d` + "\n"
+	expected := `a \(b\) c\\d\n`
+
+	if result := string(escapeString([]byte(input))); result != expected {
+		t.Errorf("escapeString(%q) = %q; want %q", input, result, expected)
+	}
+
+	if strings.Contains(string(escapeString([]byte("ok"))), `\`) {
+		t.Errorf("escapeString escaped plain text")
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		input    float64
+		expected string
+	}{
+		{595, "595"},
+		{0.5, "0.5"},
+		{1e21, "1000000000000000000000"},
+	}
+
+	for _, tt := range tests {
+		if result := formatNumber(tt.input); result != tt.expected {
+			t.Errorf("formatNumber(%v) = %q; want %q", tt.input, result, tt.expected)
+		}
+	}
+}
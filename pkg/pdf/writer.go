@@ -0,0 +1,284 @@
+// Package pdf writes PDF documents from page images in a single pass.
+//
+// Pages are written to the underlying writer as soon as they are added and
+// image data is copied straight from the files, so memory use does not grow
+// with the size of the book. Only the positions of the written objects are
+// kept until the document is closed.
+package pdf
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"io"
+	"os"
+)
+
+const (
+	// object numbers reserved for the objects written on Close
+	catalogObject = 1
+	pagesObject   = 2
+	fontObject    = 3
+)
+
+// Writer writes a PDF document page by page. It must be closed to complete
+// the document; closing it does not close the underlying writer.
+type Writer struct {
+	w       *countingWriter
+	offsets []int64
+	pages   []int
+	outline []Bookmark
+	// metadata is nil if none was set.
+	metadata   *Metadata
+	pageLabels []string
+	closed     bool
+}
+
+// NewWriter writes the PDF header to w and returns a Writer that adds pages
+// to it.
+func NewWriter(w io.Writer) (*Writer, error) {
+	pw := &Writer{
+		w:       &countingWriter{w: bufio.NewWriter(w)},
+		offsets: make([]int64, fontObject),
+	}
+
+	// the binary comment marks the file as binary for transfer programs
+	if _, err := io.WriteString(pw.w, "%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"); err != nil {
+		return nil, err
+	}
+
+	if err := pw.writeObject(fontObject, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", textLayerFont)); err != nil {
+		return nil, err
+	}
+
+	return pw, nil
+}
+
+// PageCount returns the number of pages added so far.
+func (w *Writer) PageCount() int {
+	return len(w.pages)
+}
+
+// AddJPEGPage adds a page that shows the JPEG image in filename. The page has
+// the size of the image, one pixel per point. The runs of text are put over
+// the image as invisible text, which makes the page searchable.
+func (w *Writer) AddJPEGPage(filename string, text TextLayer) error {
+	if w.closed {
+		return errors.New("pdf: writer is closed")
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	config, err := jpegConfig(f)
+	if err != nil {
+		return fmt.Errorf("could not read image %s: %w", filename, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	// the JPEG data is a valid DCTDecode stream as it is
+	imageObject := w.newObject()
+	if err := w.startObject(imageObject); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w.w, "<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n",
+		config.width, config.height, config.colorSpace, info.Size()); err != nil {
+		return err
+	}
+	if n, err := io.Copy(w.w, f); err != nil {
+		return err
+	} else if n != info.Size() {
+		return fmt.Errorf("image %s changed while reading it", filename)
+	}
+	if err := w.endStream(); err != nil {
+		return err
+	}
+
+	pageWidth, pageHeight := float64(config.width), float64(config.height)
+
+	content := fmt.Appendf(nil, "q %s 0 0 %s 0 0 cm /Im0 Do Q\n", formatNumber(pageWidth), formatNumber(pageHeight))
+	content = append(content, text.content(pageWidth, pageHeight)...)
+
+	contentObject := w.newObject()
+	if err := w.startObject(contentObject); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w.w, "<< /Length %d >>\nstream\n", len(content)); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(content); err != nil {
+		return err
+	}
+	if err := w.endStream(); err != nil {
+		return err
+	}
+
+	pageObject := w.newObject()
+	if err := w.writeObject(pageObject, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /XObject << /Im0 %d 0 R >> /Font << /%s %d 0 R >> >> /Contents %d 0 R >>",
+		pagesObject, config.width, config.height, imageObject, textLayerFontResource, fontObject, contentObject)); err != nil {
+		return err
+	}
+
+	w.pages = append(w.pages, pageObject)
+	return nil
+}
+
+// SetOutline sets the bookmarks of the document, which are written on Close.
+func (w *Writer) SetOutline(bookmarks []Bookmark) {
+	w.outline = bookmarks
+}
+
+// SetMetadata sets the metadata of the document, which is written on Close.
+func (w *Writer) SetMetadata(m Metadata) {
+	w.metadata = &m
+}
+
+// Close writes the page tree, the outline, the page labels, the metadata and
+// the cross-reference table and flushes the document to the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	if len(w.pages) == 0 {
+		return errors.New("pdf: document has no pages")
+	}
+
+	kids := make([]byte, 0, len(w.pages)*8)
+	for _, page := range w.pages {
+		kids = fmt.Appendf(kids, "%d 0 R ", page)
+	}
+	if err := w.writeObject(pagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids, len(w.pages))); err != nil {
+		return err
+	}
+
+	catalog := fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R", pagesObject)
+	if len(w.outline) > 0 {
+		outlineObject, err := w.writeOutline(w.outline)
+		if err != nil {
+			return err
+		}
+		catalog += fmt.Sprintf(" /Outlines %d 0 R /PageMode /UseOutlines", outlineObject)
+	}
+	if len(w.pageLabels) > 0 {
+		pageLabels, err := w.pageLabelsDict()
+		if err != nil {
+			return err
+		}
+		catalog += " /PageLabels " + pageLabels
+	}
+
+	trailer := fmt.Sprintf("<< /Size %d /Root %d 0 R", len(w.offsets)+1, catalogObject)
+	if w.metadata != nil {
+		infoObject, metadataObject, err := w.writeMetadata(*w.metadata)
+		if err != nil {
+			return err
+		}
+		catalog += fmt.Sprintf(" /Metadata %d 0 R", metadataObject)
+		trailer = fmt.Sprintf("<< /Size %d /Root %d 0 R /Info %d 0 R", len(w.offsets)+1, catalogObject, infoObject)
+	}
+
+	if err := w.writeObject(catalogObject, catalog+" >>"); err != nil {
+		return err
+	}
+
+	xref := w.w.n
+	if _, err := fmt.Fprintf(w.w, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1); err != nil {
+		return err
+	}
+	for _, offset := range w.offsets {
+		if _, err := fmt.Fprintf(w.w, "%010d 00000 n \n", offset); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w.w, "trailer\n%s >>\nstartxref\n%d\n%%%%EOF\n", trailer, xref); err != nil {
+		return err
+	}
+
+	return w.w.w.Flush()
+}
+
+// newObject allocates the number of a new object.
+func (w *Writer) newObject() int {
+	w.offsets = append(w.offsets, 0)
+	return len(w.offsets)
+}
+
+// startObject records the position of object n and writes its header.
+func (w *Writer) startObject(n int) error {
+	w.offsets[n-1] = w.w.n
+	_, err := fmt.Fprintf(w.w, "%d 0 obj\n", n)
+	return err
+}
+
+// writeObject writes object n with the given content.
+func (w *Writer) writeObject(n int, content string) error {
+	if err := w.startObject(n); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w.w, "%s\nendobj\n", content)
+	return err
+}
+
+func (w *Writer) endStream() error {
+	_, err := io.WriteString(w.w, "\nendstream\nendobj\n")
+	return err
+}
+
+type jpegInfo struct {
+	width      int
+	height     int
+	colorSpace string
+}
+
+// jpegConfig reads the size and color space of a JPEG image without decoding
+// it.
+func jpegConfig(r io.Reader) (jpegInfo, error) {
+	config, format, err := image.DecodeConfig(r)
+	if err != nil {
+		return jpegInfo{}, err
+	}
+	if format != "jpeg" {
+		return jpegInfo{}, fmt.Errorf("got %s image, want jpeg", format)
+	}
+
+	info := jpegInfo{width: config.Width, height: config.Height}
+	switch config.ColorModel {
+	case color.GrayModel:
+		info.colorSpace = "DeviceGray"
+	case color.CMYKModel:
+		info.colorSpace = "DeviceCMYK"
+	default:
+		info.colorSpace = "DeviceRGB"
+	}
+
+	return info, nil
+}
+
+// countingWriter counts the bytes written, which are the offsets of the
+// objects in the cross-reference table.
+type countingWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,127 @@
+package pdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pdfcpu "github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// writeTestImage writes a white image of the given size, encoded by encode.
+func writeTestImage(t *testing.T, filename string, width, height int, encode func(*os.File, image.Image) error) {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("could not create image: %v", err)
+	}
+	defer f.Close()
+
+	if err := encode(f, img); err != nil {
+		t.Fatalf("could not encode image: %v", err)
+	}
+}
+
+func writeTestJPEG(t *testing.T, filename string, width, height int) {
+	t.Helper()
+	writeTestImage(t, filename, width, height, func(f *os.File, img image.Image) error {
+		return jpeg.Encode(f, img, nil)
+	})
+}
+
+// readTestPDF parses and validates a PDF written by a Writer.
+func readTestPDF(t *testing.T, data []byte) *model.Context {
+	t.Helper()
+
+	ctx, err := pdfcpu.ReadValidateAndOptimize(bytes.NewReader(data), model.NewDefaultConfiguration())
+	if err != nil {
+		t.Fatalf("could not read pdf: %v\n%s", err, data)
+	}
+
+	return ctx
+}
+
+func TestWriter(t *testing.T) {
+	dir := t.TempDir()
+
+	sizes := [][2]int{{119, 168}, {168, 119}, {50, 50}}
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("could not create writer: %v", err)
+	}
+
+	for i, size := range sizes {
+		filename := filepath.Join(dir, string(rune('a'+i))+".jpeg")
+		writeTestJPEG(t, filename, size[0], size[1])
+
+		if err := w.AddJPEGPage(filename, TextLayer{}); err != nil {
+			t.Fatalf("could not add page %d: %v", i+1, err)
+		}
+	}
+
+	if w.PageCount() != len(sizes) {
+		t.Errorf("got page count %d, want %d", w.PageCount(), len(sizes))
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("could not close writer: %v", err)
+	}
+
+	ctx := readTestPDF(t, buf.Bytes())
+	if ctx.PageCount != len(sizes) {
+		t.Fatalf("got %d pages, want %d", ctx.PageCount, len(sizes))
+	}
+
+	dims, err := ctx.PageDims()
+	if err != nil {
+		t.Fatalf("could not get page sizes: %v", err)
+	}
+	for i, size := range sizes {
+		if dims[i].Width != float64(size[0]) || dims[i].Height != float64(size[1]) {
+			t.Errorf("page %d: got size %vx%v, want %dx%d", i+1, dims[i].Width, dims[i].Height, size[0], size[1])
+		}
+	}
+}
+
+func TestWriterNoPages(t *testing.T) {
+	w, err := NewWriter(&bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("could not create writer: %v", err)
+	}
+
+	if err := w.Close(); err == nil {
+		t.Errorf("closing a document without pages should have failed")
+	}
+}
+
+func TestWriterNotJPEG(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "page.png")
+	writeTestImage(t, filename, 10, 10, func(f *os.File, img image.Image) error {
+		return png.Encode(f, img)
+	})
+
+	w, err := NewWriter(&bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("could not create writer: %v", err)
+	}
+
+	if err := w.AddJPEGPage(filename, TextLayer{}); err == nil {
+		t.Errorf("adding a png page should have failed")
+	}
+}